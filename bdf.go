@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// bdfGlyph is one character of a BDF font
+type bdfGlyph struct {
+	advance          int // DWIDTH: columns to the next character
+	width, height    int // BBX size
+	xOffset, yOffset int
+	bitmap           [][]byte // One row of packed bits per line of the glyph
+}
+
+// bdfFont is a bitmap font in the X11 Glyph Bitmap Distribution Format.
+// Each pixel becomes one terminal cell.
+type bdfFont struct {
+	ascent, descent int
+	cellWidth       int
+	defaultChar     rune
+	glyphs          map[rune]*bdfGlyph
+}
+
+// parseBDF reads the properties and characters of a BDF font
+func parseBDF(data []byte) (*bdfFont, error) {
+	f := &bdfFont{glyphs: make(map[rune]*bdfGlyph), defaultChar: -1}
+	var boundsHeight, boundsY int
+
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	var g *bdfGlyph
+	encoding := rune(-1)
+	inBitmap := false
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if inBitmap {
+			if fields[0] == "ENDCHAR" {
+				inBitmap = false
+				if encoding >= 0 {
+					f.glyphs[encoding] = g
+				}
+				continue
+			}
+			row, err := hex.DecodeString(fields[0])
+			if err != nil {
+				return nil, fmt.Errorf("bad BDF bitmap row %q", fields[0])
+			}
+			g.bitmap = append(g.bitmap, row)
+			continue
+		}
+
+		ints := func(n int) ([]int, bool) {
+			if len(fields) < n+1 {
+				return nil, false
+			}
+			vals := make([]int, n)
+			for i := range vals {
+				v, err := strconv.Atoi(fields[i+1])
+				if err != nil {
+					return nil, false
+				}
+				vals[i] = v
+			}
+			return vals, true
+		}
+
+		switch fields[0] {
+		case "FONTBOUNDINGBOX":
+			if v, ok := ints(4); ok {
+				f.cellWidth, boundsHeight, boundsY = v[0], v[1], v[3]
+			}
+		case "FONT_ASCENT":
+			if v, ok := ints(1); ok {
+				f.ascent = v[0]
+			}
+		case "FONT_DESCENT":
+			if v, ok := ints(1); ok {
+				f.descent = v[0]
+			}
+		case "DEFAULT_CHAR":
+			if v, ok := ints(1); ok {
+				f.defaultChar = rune(v[0])
+			}
+		case "STARTCHAR":
+			g = &bdfGlyph{}
+			encoding = -1
+		case "ENCODING":
+			if v, ok := ints(1); ok {
+				encoding = rune(v[0])
+			}
+		case "DWIDTH":
+			if v, ok := ints(2); ok && g != nil {
+				g.advance = v[0]
+			}
+		case "BBX":
+			if v, ok := ints(4); ok && g != nil {
+				g.width, g.height, g.xOffset, g.yOffset = v[0], v[1], v[2], v[3]
+			}
+		case "BITMAP":
+			if g == nil {
+				return nil, errors.New("BDF bitmap outside a character")
+			}
+			inBitmap = true
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(f.glyphs) == 0 {
+		return nil, errors.New("BDF font has no characters")
+	}
+
+	// Fonts without ascent/descent properties fall back to the bounding box
+	if f.ascent+f.descent == 0 {
+		f.ascent, f.descent = boundsHeight+boundsY, -boundsY
+	}
+	// Size text by a typical letter; the bounding box is as wide as the
+	// widest glyph, which is double width in fonts like Unifont
+	if m, ok := f.glyphs['M']; ok && m.advance > 0 {
+		f.cellWidth = m.advance
+	}
+	return f, nil
+}
+
+func (f *bdfFont) Height() int    { return max(f.ascent+f.descent, 1) }
+func (f *bdfFont) CellWidth() int { return max(f.cellWidth, 1) }
+
+// glyph draws r as rows of full blocks, advance columns wide with the
+// baseline ascent rows from the top
+func (f *bdfFont) glyph(r rune) []string {
+	g, ok := f.glyphs[r]
+	if !ok {
+		if g, ok = f.glyphs[f.defaultChar]; !ok {
+			return boxGlyph(f.CellWidth(), f.Height(), '█')
+		}
+	}
+
+	width := max(g.advance, g.xOffset+g.width, 1)
+	cells := make([][]rune, f.Height())
+	for i := range cells {
+		cells[i] = []rune(strings.Repeat(" ", width))
+	}
+	top := f.ascent - g.yOffset - g.height
+	for y, row := range g.bitmap {
+		cy := top + y
+		if cy < 0 || cy >= len(cells) {
+			continue
+		}
+		for x := 0; x < g.width && x/8 < len(row); x++ {
+			cx := g.xOffset + x
+			if cx >= 0 && cx < width && row[x/8]&(0x80>>(x%8)) != 0 {
+				cells[cy][cx] = '█'
+			}
+		}
+	}
+
+	rows := make([]string, len(cells))
+	for i, c := range cells {
+		rows[i] = string(c)
+	}
+	return rows
+}
+
+func (f *bdfFont) Render(runes []rune) ([]string, []glyphSpan) {
+	glyphs := make([][]string, len(runes))
+	for i, r := range runes {
+		glyphs[i] = f.glyph(r)
+	}
+	return joinGlyphs(f.Height(), glyphs)
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Smart chunking joins short, very common words to the word after them:
+// "of the", "in a"
+const (
+	shortWord  = 3 // Most letters in a joined word
+	commonZipf = 6 // Least Zipf frequency of a joined word
+)
+
+// chunker groups words into the flashes they are shown in. Positions stay
+// word indexes, so navigation and bookmarks are unaffected.
+type chunker struct {
+	Size   int // Words per flash, 0 to group short words
+	MaxLen int // Longest flash in characters, so it fits at the uniform scale
+}
+
+// parseChunk reads the -chunk flag: a number of words or "smart"
+func parseChunk(s string) (size int, ok bool) {
+	if s == "smart" {
+		return 0, true
+	}
+	size, err := strconv.Atoi(s)
+	return size, err == nil && size >= 1
+}
+
+// end returns the index after the last word of the flash starting at i.
+// A flash never spans the end of a clause or sentence.
+func (c chunker) end(words []token, i int) int {
+	length := len(displayRunes(words[i].Text))
+	j := i + 1
+	for ; j < len(words); j++ {
+		prev := words[j-1]
+		if c.Size > 0 && j-i >= c.Size {
+			break
+		}
+		if c.Size == 0 && !isFunctionWord(prev.Text) {
+			break
+		}
+		if words[j].Sentence != prev.Sentence || endsWithPunctuation(prev.Text) {
+			break
+		}
+		length += 1 + len(displayRunes(words[j].Text))
+		if length > c.MaxLen {
+			break
+		}
+	}
+	return j
+}
+
+// isFunctionWord reports whether word is short and common enough to be
+// read together with the word after it
+func isFunctionWord(word string) bool {
+	return len(letters(word)) <= shortWord && zipfFrequency(word) >= commonZipf
+}
+
+// chunkText is the text of the flash of words[i:j]
+func chunkText(words []token, i, j int) string {
+	texts := make([]string, 0, j-i)
+	for _, w := range words[i:j] {
+		texts = append(texts, w.Text)
+	}
+	return strings.Join(texts, " ")
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"maps"
+	"slices"
+	"sort"
+	"unicode"
+	"unicode/utf8"
+)
+
+// token is one word of the document along with where it sits in the
+// structure of the source text
+type token struct {
+	Text       string
+	Offset     int  // Byte offset of the word in the source text
+	Paragraph  int  // Paragraph index, counted from 0
+	Sentence   int  // Sentence index across the whole document
+	Section    int  // Index into document.Sections, -1 before the first section
+	Page       int  // Page index, -1 for unpaginated sources
+	Continued  bool // A piece of a long word that goes on in the next token
+	Hyphenated bool // Continued with a hyphen that isn't in the source
+}
+
+// docSection is a chapter or heading resolved to the token it starts at
+type docSection struct {
+	Title string
+	Level int
+	Start int // Index of the first token in the section
+}
+
+// document is the tokenized form of a source that the reader works from
+type document struct {
+	Tokens   []token
+	Sections []docSection
+	Pages    []int // Index of the first token on each page
+}
+
+// chapters returns the indexes of the sections that are chapters: those at
+// the shallowest level with more than one section, so that a lone title
+// over a document's headings isn't its only chapter. Deeper headings belong
+// to the chapter they are in.
+func (d *document) chapters() []int {
+	count := make(map[int]int)
+	for _, s := range d.Sections {
+		count[s.Level]++
+	}
+	levels := slices.Sorted(maps.Keys(count))
+	if len(levels) == 0 {
+		return nil
+	}
+	top := levels[0]
+	for _, level := range levels {
+		if count[level] > 1 {
+			top = level
+			break
+		}
+	}
+	var chapters []int
+	for i, s := range d.Sections {
+		if s.Level == top {
+			chapters = append(chapters, i)
+		}
+	}
+	return chapters
+}
+
+// buildDocument splits source text into words, keeping paragraph breaks
+// (blank lines), sentence boundaries, sections and pages.
+func buildDocument(src source) *document {
+	doc := &document{}
+	text := src.Text
+
+	sectionIdx, pageIdx := -1, -1
+	paragraph, sentence := 0, 0
+	newlines := 0 // Newlines seen since the previous word
+	for pos := 0; pos < len(text); {
+		r, size := utf8.DecodeRuneInString(text[pos:])
+		if unicode.IsSpace(r) {
+			if r == '\n' {
+				newlines++
+			}
+			pos += size
+			continue
+		}
+
+		start := pos
+		for pos < len(text) {
+			r, size := utf8.DecodeRuneInString(text[pos:])
+			if unicode.IsSpace(r) {
+				break
+			}
+			pos += size
+		}
+
+		if len(doc.Tokens) > 0 {
+			prev := doc.Tokens[len(doc.Tokens)-1]
+			if newlines >= 2 {
+				paragraph++
+				sentence++
+			} else if endsWithSentence(prev.Text) {
+				sentence++
+			}
+		}
+		newlines = 0
+
+		for sectionIdx+1 < len(src.Sections) && src.Sections[sectionIdx+1].Offset <= start {
+			sectionIdx++
+			doc.Sections = append(doc.Sections, docSection{
+				Title: src.Sections[sectionIdx].Title,
+				Level: src.Sections[sectionIdx].Level,
+				Start: len(doc.Tokens),
+			})
+		}
+		for pageIdx+1 < len(src.Pages) && src.Pages[pageIdx+1] <= start {
+			pageIdx++
+			doc.Pages = append(doc.Pages, len(doc.Tokens))
+		}
+
+		doc.Tokens = append(doc.Tokens, token{
+			Text:      text[start:pos],
+			Offset:    start,
+			Paragraph: paragraph,
+			Sentence:  sentence,
+			Section:   len(doc.Sections) - 1,
+			Page:      len(doc.Pages) - 1,
+		})
+	}
+
+	// Trailing pages without text still count towards the page total
+	for pageIdx+1 < len(src.Pages) {
+		pageIdx++
+		doc.Pages = append(doc.Pages, len(doc.Tokens))
+	}
+	return doc
+}
+
+// indexAtOffset returns the index of the token containing or following
+// byte offset, for restoring positions saved as offsets
+func (d *document) indexAtOffset(offset int) int {
+	i := sort.Search(len(d.Tokens), func(i int) bool {
+		return d.Tokens[i].Offset+len(d.Tokens[i].Text) > offset
+	})
+	if i >= len(d.Tokens) {
+		i = len(d.Tokens) - 1
+	}
+	return max(i, 0)
+}
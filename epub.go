@@ -0,0 +1,303 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// EPUB container and package document structures (only the parts we need)
+type epubContainer struct {
+	Rootfiles []struct {
+		FullPath string `xml:"full-path,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+type epubPackage struct {
+	Manifest []struct {
+		ID         string `xml:"id,attr"`
+		Href       string `xml:"href,attr"`
+		MediaType  string `xml:"media-type,attr"`
+		Properties string `xml:"properties,attr"`
+	} `xml:"manifest>item"`
+	Spine struct {
+		Toc      string `xml:"toc,attr"`
+		Itemrefs []struct {
+			IDRef  string `xml:"idref,attr"`
+			Linear string `xml:"linear,attr"`
+		} `xml:"itemref"`
+	} `xml:"spine"`
+}
+
+type ncxNavPoint struct {
+	Label   string `xml:"navLabel>text"`
+	Content struct {
+		Src string `xml:"src,attr"`
+	} `xml:"content"`
+	Children []ncxNavPoint `xml:"navPoint"`
+}
+
+type ncxDocument struct {
+	NavPoints []ncxNavPoint `xml:"navMap>navPoint"`
+}
+
+// tocEntry is one entry of an EPUB table of contents
+type tocEntry struct {
+	Title    string
+	Path     string // Archive path of the target document
+	Fragment string // Element id within the document, if any
+	Level    int
+}
+
+// isZip reports whether data starts with a zip local file header
+func isZip(data []byte) bool {
+	return bytes.HasPrefix(data, []byte("PK\x03\x04"))
+}
+
+// isEPUB reports whether the zip archive looks like an EPUB
+func isEPUB(zr *zip.Reader) bool {
+	return zipFile(zr, "META-INF/container.xml") != nil
+}
+
+func zipFile(zr *zip.Reader, name string) *zip.File {
+	for _, f := range zr.File {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+func readZipFile(zr *zip.Reader, name string) ([]byte, error) {
+	f := zipFile(zr, name)
+	if f == nil {
+		return nil, fmt.Errorf("missing %s", name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+// resolveHref resolves an href relative to the directory of base, returning
+// the archive path and the fragment (if any)
+func resolveHref(base, href string) (string, string) {
+	href, fragment, _ := strings.Cut(href, "#")
+	if unescaped, err := url.PathUnescape(href); err == nil {
+		href = unescaped
+	}
+	if href == "" {
+		return base, fragment
+	}
+	return path.Join(path.Dir(base), href), fragment
+}
+
+// readEPUB extracts the text of an EPUB in spine order, with a section for
+// every table of contents entry (or for every spine document if there is no TOC).
+func readEPUB(zr *zip.Reader) (source, error) {
+	data, err := readZipFile(zr, "META-INF/container.xml")
+	if err != nil {
+		return source{}, fmt.Errorf("invalid EPUB: %w", err)
+	}
+	var container epubContainer
+	if err := xml.Unmarshal(data, &container); err != nil {
+		return source{}, fmt.Errorf("invalid EPUB container: %w", err)
+	}
+	if len(container.Rootfiles) == 0 {
+		return source{}, fmt.Errorf("invalid EPUB: no rootfile in container")
+	}
+	opfPath := container.Rootfiles[0].FullPath
+
+	data, err = readZipFile(zr, opfPath)
+	if err != nil {
+		return source{}, fmt.Errorf("invalid EPUB: %w", err)
+	}
+	var pkg epubPackage
+	if err := xml.Unmarshal(data, &pkg); err != nil {
+		return source{}, fmt.Errorf("invalid EPUB package: %w", err)
+	}
+
+	hrefs := make(map[string]string)
+	var navPath, ncxPath string
+	for _, item := range pkg.Manifest {
+		p, _ := resolveHref(opfPath, item.Href)
+		hrefs[item.ID] = p
+		if strings.Contains(" "+item.Properties+" ", " nav ") {
+			navPath = p
+		}
+		if item.MediaType == "application/x-dtbncx+xml" && (pkg.Spine.Toc == "" || item.ID == pkg.Spine.Toc) {
+			ncxPath = p
+		}
+	}
+
+	// Prefer the EPUB 3 navigation document, falling back to the EPUB 2 NCX
+	var toc []tocEntry
+	if navPath != "" {
+		toc = readNavTOC(zr, navPath)
+	}
+	if len(toc) == 0 && ncxPath != "" {
+		toc = readNCXTOC(zr, ncxPath)
+	}
+	tocByPath := make(map[string][]tocEntry)
+	for _, e := range toc {
+		tocByPath[e.Path] = append(tocByPath[e.Path], e)
+	}
+
+	var src source
+	var text strings.Builder
+	for _, ref := range pkg.Spine.Itemrefs {
+		if ref.Linear == "no" {
+			continue
+		}
+		docPath, ok := hrefs[ref.IDRef]
+		if !ok {
+			continue
+		}
+		data, err := readZipFile(zr, docPath)
+		if err != nil {
+			continue
+		}
+		doc, err := extractHTMLText(bytes.NewReader(data))
+		if err != nil || strings.TrimSpace(doc.Text) == "" {
+			continue
+		}
+
+		if text.Len() > 0 {
+			text.WriteString("\n\n")
+		}
+		start := text.Len()
+		text.WriteString(doc.Text)
+
+		entries := tocByPath[docPath]
+		if len(toc) == 0 {
+			title := doc.Title
+			if title == "" {
+				title = fmt.Sprintf("Section %d", len(src.Sections)+1)
+			}
+			entries = []tocEntry{{Title: title}}
+		}
+		for _, e := range entries {
+			offset := start
+			if e.Fragment != "" {
+				if anchor, ok := doc.Anchors[e.Fragment]; ok {
+					offset = start + anchor
+				}
+			}
+			src.Sections = append(src.Sections, section{Title: e.Title, Offset: offset, Level: e.Level})
+		}
+	}
+
+	src.Text = text.String()
+	if strings.TrimSpace(src.Text) == "" {
+		return source{}, fmt.Errorf("EPUB contains no readable text")
+	}
+	sortSections(src.Sections)
+	return src, nil
+}
+
+// readNCXTOC reads the table of contents from an EPUB 2 NCX file
+func readNCXTOC(zr *zip.Reader, ncxPath string) []tocEntry {
+	data, err := readZipFile(zr, ncxPath)
+	if err != nil {
+		return nil
+	}
+	var ncx ncxDocument
+	if err := xml.Unmarshal(data, &ncx); err != nil {
+		return nil
+	}
+
+	var toc []tocEntry
+	var walk func(points []ncxNavPoint, level int)
+	walk = func(points []ncxNavPoint, level int) {
+		for _, p := range points {
+			docPath, fragment := resolveHref(ncxPath, p.Content.Src)
+			toc = append(toc, tocEntry{
+				Title:    strings.Join(strings.Fields(p.Label), " "),
+				Path:     docPath,
+				Fragment: fragment,
+				Level:    level,
+			})
+			walk(p.Children, level+1)
+		}
+	}
+	walk(ncx.NavPoints, 0)
+	return toc
+}
+
+// readNavTOC reads the table of contents from an EPUB 3 navigation document
+func readNavTOC(zr *zip.Reader, navPath string) []tocEntry {
+	data, err := readZipFile(zr, navPath)
+	if err != nil {
+		return nil
+	}
+	doc, err := html.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil
+	}
+
+	// Find <nav epub:type="toc">, or the first <nav> if none is marked
+	var tocNav, firstNav *html.Node
+	var find func(*html.Node)
+	find = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.DataAtom == atom.Nav {
+			if firstNav == nil {
+				firstNav = n
+			}
+			for _, attr := range n.Attr {
+				if (attr.Key == "epub:type" || attr.Key == "type") && strings.Contains(" "+attr.Val+" ", " toc ") {
+					tocNav = n
+					return
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil && tocNav == nil; c = c.NextSibling {
+			find(c)
+		}
+	}
+	find(doc)
+	if tocNav == nil {
+		tocNav = firstNav
+	}
+	if tocNav == nil {
+		return nil
+	}
+
+	var toc []tocEntry
+	var walk func(n *html.Node, level int)
+	walk = func(n *html.Node, level int) {
+		if n.Type == html.ElementNode {
+			switch n.DataAtom {
+			case atom.Ol, atom.Ul:
+				level++
+			case atom.A:
+				for _, attr := range n.Attr {
+					if attr.Key == "href" {
+						docPath, fragment := resolveHref(navPath, attr.Val)
+						toc = append(toc, tocEntry{
+							Title:    nodeText(n),
+							Path:     docPath,
+							Fragment: fragment,
+							Level:    max(level-1, 0),
+						})
+					}
+				}
+				return
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c, level)
+		}
+	}
+	walk(tocNav, 0)
+	return toc
+}
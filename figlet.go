@@ -0,0 +1,329 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Horizontal layout bits of a FIGlet font's full_layout header field
+const (
+	smushEqual     = 1
+	smushLowline   = 2
+	smushHierarchy = 4
+	smushPair      = 8
+	smushBigX      = 16
+	smushHardblank = 32
+	layoutKern     = 64
+	layoutSmush    = 128
+)
+
+// Characters every FIGlet font defines after printable ASCII, in order
+var figletDeutsch = []rune{'Ä', 'Ö', 'Ü', 'ä', 'ö', 'ü', 'ß'}
+
+// figletFont is a font in the FIGlet .flf format
+type figletFont struct {
+	height      int
+	hardblank   rune
+	layout      int
+	rightToLeft bool
+	glyphs      map[rune][][]rune
+	cellWidth   int
+}
+
+// parseFIGlet reads a FIGlet font: a header line, comments, then one glyph
+// per character with every row ending in an end mark
+func parseFIGlet(data []byte) (*figletFont, error) {
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	if !sc.Scan() {
+		return nil, errors.New("empty FIGlet font")
+	}
+	header := strings.Fields(sc.Text())
+	if len(header) < 6 || !strings.HasPrefix(header[0], "flf2a") || len(header[0]) < 6 {
+		return nil, errors.New("bad FIGlet header")
+	}
+	nums := make([]int, len(header)-1)
+	for i, f := range header[1:] {
+		n, err := strconv.Atoi(f)
+		if err != nil {
+			if i < 5 {
+				return nil, fmt.Errorf("bad FIGlet header field %q", f)
+			}
+			break
+		}
+		nums[i] = n
+	}
+
+	f := &figletFont{
+		height:    nums[0],
+		hardblank: []rune(header[0][5:])[0],
+		glyphs:    make(map[rune][][]rune),
+	}
+	if f.height < 1 {
+		return nil, errors.New("bad FIGlet height")
+	}
+	oldLayout, comments := nums[3], nums[4]
+	if len(header) > 6 {
+		f.rightToLeft = nums[5] == 1
+	}
+	switch {
+	case len(header) > 7:
+		f.layout = nums[6]
+	case oldLayout < 0:
+		f.layout = 0
+	case oldLayout == 0:
+		f.layout = layoutKern
+	default:
+		f.layout = oldLayout&31 | layoutSmush
+	}
+
+	for range comments {
+		sc.Scan()
+	}
+
+	readGlyph := func() ([][]rune, bool) {
+		rows := make([][]rune, f.height)
+		for i := range rows {
+			if !sc.Scan() {
+				return nil, false
+			}
+			line := strings.TrimRight(sc.Text(), "\r")
+			if line != "" {
+				// Strip the end mark, doubled on the last row
+				mark := line[len(line)-1:]
+				line = strings.TrimRight(line, mark)
+			}
+			rows[i] = []rune(line)
+		}
+		return rows, true
+	}
+
+	for r := rune(32); r <= 126; r++ {
+		g, ok := readGlyph()
+		if !ok {
+			return nil, fmt.Errorf("FIGlet font ends at character %q", r)
+		}
+		f.glyphs[r] = g
+	}
+	for _, r := range figletDeutsch {
+		g, ok := readGlyph()
+		if !ok {
+			break
+		}
+		f.glyphs[r] = g
+	}
+	// Code-tagged characters: a line with the code, then the glyph
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		code, err := strconv.ParseInt(fields[0], 0, 32)
+		if err != nil {
+			break
+		}
+		g, ok := readGlyph()
+		if !ok {
+			break
+		}
+		if code >= 0 {
+			f.glyphs[rune(code)] = g
+		}
+	}
+
+	for r := rune('A'); r <= 'Z'; r++ {
+		f.cellWidth = max(f.cellWidth, f.glyphWidth(f.glyphs[r]))
+	}
+	return f, nil
+}
+
+func (f *figletFont) Height() int    { return f.height }
+func (f *figletFont) CellWidth() int { return max(f.cellWidth, 1) }
+
+func (f *figletFont) glyphWidth(g [][]rune) int {
+	width := 0
+	for _, row := range g {
+		width = max(width, len(row))
+	}
+	return width
+}
+
+// glyph returns the rows for r padded to a common width. Missing characters
+// use the font's character 0 if it has one, as figlet does.
+func (f *figletFont) glyph(r rune) [][]rune {
+	g, ok := f.glyphs[r]
+	if !ok {
+		if g, ok = f.glyphs[0]; !ok {
+			box := boxGlyph(f.CellWidth(), f.height, '#')
+			g = make([][]rune, len(box))
+			for i, row := range box {
+				g[i] = []rune(row)
+			}
+		}
+	}
+	width := f.glyphWidth(g)
+	rows := make([][]rune, f.height)
+	for i := range rows {
+		rows[i] = []rune(strings.Repeat(" ", width))
+		if i < len(g) {
+			copy(rows[i], g[i])
+		}
+	}
+	return rows
+}
+
+// Render lays characters out with the font's kerning and smushing rules
+func (f *figletFont) Render(runes []rune) ([]string, []glyphSpan) {
+	out := make([][]rune, f.height)
+	spans := make([]glyphSpan, len(runes))
+	prevWidth := 0
+
+	for n := range runes {
+		// Right-to-left fonts are laid out from the last character
+		i := n
+		if f.rightToLeft {
+			i = len(runes) - 1 - n
+		}
+		g := f.glyph(runes[i])
+		width := f.glyphWidth(g)
+		overlap := f.smushAmount(out, g, prevWidth, width)
+		start := max(len(out[0])-overlap, 0)
+		for row := range out {
+			line := out[row]
+			for k := 0; k < overlap && k < len(g[row]); k++ {
+				col := len(line) - overlap + k
+				if col < 0 {
+					continue
+				}
+				line[col] = f.smush(line[col], g[row][k], prevWidth, width)
+			}
+			out[row] = append(line, g[row][min(overlap, len(g[row])):]...)
+		}
+		spans[i] = glyphSpan{start, len(out[0])}
+		prevWidth = width
+	}
+
+	rows := make([]string, f.height)
+	for i, line := range out {
+		rows[i] = strings.ReplaceAll(string(line), string(f.hardblank), " ")
+	}
+	return rows, spans
+}
+
+// smushAmount is how many columns glyph g can move left into out, following
+// figlet's smushamt: trailing and leading blanks close up, and one more
+// column overlaps where the touching characters smush
+func (f *figletFont) smushAmount(out, g [][]rune, prevWidth, width int) int {
+	if f.layout&(layoutSmush|layoutKern) == 0 || len(out[0]) == 0 {
+		return 0
+	}
+	amount := width
+	for row := range out {
+		line := out[row]
+		end := len(line) - 1
+		for end > 0 && line[end] == ' ' {
+			end--
+		}
+		begin := 0
+		for begin < len(g[row]) && g[row][begin] == ' ' {
+			begin++
+		}
+		amt := begin + len(line) - 1 - end
+		left := line[end]
+		switch {
+		case left == ' ':
+			amt++
+		case begin < len(g[row]):
+			if f.smush(left, g[row][begin], prevWidth, width) != 0 {
+				amt++
+			}
+		}
+		amount = min(amount, amt)
+	}
+	return max(amount, 0)
+}
+
+// smush returns the character that replaces left and right when they
+// overlap, or 0 if they can't, following figlet's smushem
+func (f *figletFont) smush(left, right rune, prevWidth, width int) rune {
+	switch {
+	case left == ' ':
+		return right
+	case right == ' ':
+		return left
+	case prevWidth < 2 || width < 2:
+		return 0
+	case f.layout&layoutSmush == 0:
+		return 0
+	}
+
+	if f.layout&63 == 0 {
+		// Universal smushing: the later character wins, except over hardblanks
+		switch {
+		case left == f.hardblank:
+			return right
+		case right == f.hardblank:
+			return left
+		case f.rightToLeft:
+			return left
+		}
+		return right
+	}
+
+	if f.layout&smushHardblank != 0 && left == f.hardblank && right == f.hardblank {
+		return left
+	}
+	if left == f.hardblank || right == f.hardblank {
+		return 0
+	}
+	if f.layout&smushEqual != 0 && left == right {
+		return left
+	}
+	if f.layout&smushLowline != 0 {
+		if left == '_' && strings.ContainsRune(`|/\[]{}()<>`, right) {
+			return right
+		}
+		if right == '_' && strings.ContainsRune(`|/\[]{}()<>`, left) {
+			return left
+		}
+	}
+	if f.layout&smushHierarchy != 0 {
+		classes := []string{"|", `/\`, "[]", "{}", "()", "<>"}
+		lc, rc := -1, -1
+		for i, c := range classes {
+			if strings.ContainsRune(c, left) {
+				lc = i
+			}
+			if strings.ContainsRune(c, right) {
+				rc = i
+			}
+		}
+		if lc >= 0 && rc >= 0 && lc != rc {
+			if rc > lc {
+				return right
+			}
+			return left
+		}
+	}
+	if f.layout&smushPair != 0 {
+		switch string([]rune{left, right}) {
+		case "[]", "][", "{}", "}{", "()", ")(":
+			return '|'
+		}
+	}
+	if f.layout&smushBigX != 0 {
+		switch string([]rune{left, right}) {
+		case `/\`:
+			return '|'
+		case `\/`:
+			return 'Y'
+		case "><":
+			return 'X'
+		}
+	}
+	return 0
+}
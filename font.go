@@ -0,0 +1,142 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// fontFace draws words as rows of text art
+type fontFace interface {
+	// Height is the number of rows every rendered word has
+	Height() int
+	// CellWidth is the typical width of one character, used to size text
+	// to the terminal before a word is rendered
+	CellWidth() int
+	// Render draws runes side by side. Every row has the same width; spans
+	// gives the columns each rune occupies so the ORP can be lined up.
+	Render(runes []rune) (rows []string, spans []glyphSpan)
+}
+
+// glyphSpan is the column range [Start, End) a character occupies in a
+// rendered word
+type glyphSpan struct {
+	Start, End int
+}
+
+// builtinFont is the block font compiled into the program
+type builtinFont struct{}
+
+func (builtinFont) Height() int    { return builtinHeight }
+func (builtinFont) CellWidth() int { return builtinWidth }
+
+func (builtinFont) Render(runes []rune) ([]string, []glyphSpan) {
+	glyphs := make([][]string, len(runes))
+	for i, r := range runes {
+		glyphs[i] = glyph(r)
+	}
+	return joinGlyphs(builtinHeight, glyphs)
+}
+
+// uppercaseFont draws every word in capitals
+type uppercaseFont struct {
+	fontFace
+}
+
+func (f uppercaseFont) Render(runes []rune) ([]string, []glyphSpan) {
+	upper := make([]rune, len(runes))
+	for i, r := range runes {
+		upper[i] = unicode.ToUpper(r)
+	}
+	return f.fontFace.Render(upper)
+}
+
+// joinGlyphs places glyphs next to each other without overlap. Each glyph
+// has height rows of equal width.
+func joinGlyphs(height int, glyphs [][]string) ([]string, []glyphSpan) {
+	rows := make([]strings.Builder, height)
+	spans := make([]glyphSpan, len(glyphs))
+	col := 0
+	for i, g := range glyphs {
+		width := 0
+		if len(g) > 0 {
+			width = len([]rune(g[0]))
+		}
+		for row := range rows {
+			if row < len(g) {
+				rows[row].WriteString(g[row])
+			} else {
+				rows[row].WriteString(strings.Repeat(" ", width))
+			}
+		}
+		spans[i] = glyphSpan{col, col + width}
+		col += width
+	}
+
+	lines := make([]string, height)
+	for i := range rows {
+		lines[i] = rows[i].String()
+	}
+	return lines, spans
+}
+
+// boxGlyph draws an outlined box, the placeholder for characters missing
+// from a loaded font
+func boxGlyph(width, height int, edge rune) []string {
+	width, height = max(width, 3), max(height, 3)
+	rows := make([]string, height)
+	full := strings.Repeat(string(edge), width)
+	hollow := string(edge) + strings.Repeat(" ", width-2) + string(edge)
+	for i := range rows {
+		if i == 0 || i == height-1 {
+			rows[i] = full
+		} else {
+			rows[i] = hollow
+		}
+	}
+	return rows
+}
+
+// loadFont reads a FIGlet (.flf) or BDF (.bdf) font file. FIGlet fonts
+// may also be zipped, as distributed with figlet 2.2.
+func loadFont(path string) (fontFace, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if isZip(data) {
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid font %s: %w", path, err)
+		}
+		if len(zr.File) == 0 {
+			return nil, fmt.Errorf("invalid font %s: empty archive", path)
+		}
+		if data, err = readZipFile(zr, zr.File[0].Name); err != nil {
+			return nil, fmt.Errorf("invalid font %s: %w", path, err)
+		}
+	}
+
+	var face fontFace
+	switch {
+	case bytes.HasPrefix(data, []byte("flf2a")):
+		face, err = parseFIGlet(data)
+	case bytes.HasPrefix(data, []byte("STARTFONT")):
+		face, err = parseBDF(data)
+	default:
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".flf", ".bdf":
+			err = fmt.Errorf("missing %s header", strings.ToUpper(filepath.Ext(path)[1:]))
+		default:
+			err = fmt.Errorf("not a FIGlet or BDF font")
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid font %s: %w", path, err)
+	}
+	return face, nil
+}
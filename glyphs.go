@@ -0,0 +1,1611 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// ASCII art font - capitals are 5 rows tall, drawn by glyph with room
+// for accents above and marks below
+var font = map[rune][]string{
+	'A': {
+		"  █████  ",
+		" ██   ██ ",
+		" ███████ ",
+		" ██   ██ ",
+		" ██   ██ ",
+	},
+	'B': {
+		" ██████  ",
+		" ██   ██ ",
+		" ██████  ",
+		" ██   ██ ",
+		" ██████  ",
+	},
+	'C': {
+		"  █████  ",
+		" ██      ",
+		" ██      ",
+		" ██      ",
+		"  █████  ",
+	},
+	'D': {
+		" ██████  ",
+		" ██   ██ ",
+		" ██   ██ ",
+		" ██   ██ ",
+		" ██████  ",
+	},
+	'E': {
+		" ███████ ",
+		" ██      ",
+		" █████   ",
+		" ██      ",
+		" ███████ ",
+	},
+	'F': {
+		" ███████ ",
+		" ██      ",
+		" █████   ",
+		" ██      ",
+		" ██      ",
+	},
+	'G': {
+		"  █████  ",
+		" ██      ",
+		" ██  ███ ",
+		" ██   ██ ",
+		"  █████  ",
+	},
+	'H': {
+		" ██   ██ ",
+		" ██   ██ ",
+		" ███████ ",
+		" ██   ██ ",
+		" ██   ██ ",
+	},
+	'I': {
+		" ███████ ",
+		"   ██    ",
+		"   ██    ",
+		"   ██    ",
+		" ███████ ",
+	},
+	'J': {
+		" ███████ ",
+		"     ██  ",
+		"     ██  ",
+		" ██  ██  ",
+		"  ████   ",
+	},
+	'K': {
+		" ██   ██ ",
+		" ██  ██  ",
+		" █████   ",
+		" ██  ██  ",
+		" ██   ██ ",
+	},
+	'L': {
+		" ██      ",
+		" ██      ",
+		" ██      ",
+		" ██      ",
+		" ███████ ",
+	},
+	'M': {
+		" ██   ██ ",
+		" ███ ███ ",
+		" ██ █ ██ ",
+		" ██   ██ ",
+		" ██   ██ ",
+	},
+	'N': {
+		" ██   ██ ",
+		" ███  ██ ",
+		" ██ █ ██ ",
+		" ██  ███ ",
+		" ██   ██ ",
+	},
+	'O': {
+		"  █████  ",
+		" ██   ██ ",
+		" ██   ██ ",
+		" ██   ██ ",
+		"  █████  ",
+	},
+	'P': {
+		" ██████  ",
+		" ██   ██ ",
+		" ██████  ",
+		" ██      ",
+		" ██      ",
+	},
+	'Q': {
+		"  █████  ",
+		" ██   ██ ",
+		" ██   ██ ",
+		" ██  ██  ",
+		"  ████ █ ",
+	},
+	'R': {
+		" ██████  ",
+		" ██   ██ ",
+		" ██████  ",
+		" ██  ██  ",
+		" ██   ██ ",
+	},
+	'S': {
+		"  █████  ",
+		" ██      ",
+		"  █████  ",
+		"      ██ ",
+		"  █████  ",
+	},
+	'T': {
+		" ███████ ",
+		"   ██    ",
+		"   ██    ",
+		"   ██    ",
+		"   ██    ",
+	},
+	'U': {
+		" ██   ██ ",
+		" ██   ██ ",
+		" ██   ██ ",
+		" ██   ██ ",
+		"  █████  ",
+	},
+	'V': {
+		" ██   ██ ",
+		" ██   ██ ",
+		" ██   ██ ",
+		"  ██ ██  ",
+		"   ███   ",
+	},
+	'W': {
+		" ██   ██ ",
+		" ██   ██ ",
+		" ██ █ ██ ",
+		" ███ ███ ",
+		" ██   ██ ",
+	},
+	'X': {
+		" ██   ██ ",
+		"  ██ ██  ",
+		"   ███   ",
+		"  ██ ██  ",
+		" ██   ██ ",
+	},
+	'Y': {
+		" ██   ██ ",
+		"  ██ ██  ",
+		"   ███   ",
+		"   ██    ",
+		"   ██    ",
+	},
+	'Z': {
+		" ███████ ",
+		"     ██  ",
+		"   ██    ",
+		"  ██     ",
+		" ███████ ",
+	},
+	'0': {
+		"  █████  ",
+		" ██  ███ ",
+		" ██ █ ██ ",
+		" ███  ██ ",
+		"  █████  ",
+	},
+	'1': {
+		"   ██    ",
+		"  ███    ",
+		"   ██    ",
+		"   ██    ",
+		" ███████ ",
+	},
+	'2': {
+		"  █████  ",
+		" ██   ██ ",
+		"    ██   ",
+		"  ██     ",
+		" ███████ ",
+	},
+	'3': {
+		"  █████  ",
+		"      ██ ",
+		"   ████  ",
+		"      ██ ",
+		"  █████  ",
+	},
+	'4': {
+		" ██   ██ ",
+		" ██   ██ ",
+		" ███████ ",
+		"      ██ ",
+		"      ██ ",
+	},
+	'5': {
+		" ███████ ",
+		" ██      ",
+		" ██████  ",
+		"      ██ ",
+		" ██████  ",
+	},
+	'6': {
+		"  █████  ",
+		" ██      ",
+		" ██████  ",
+		" ██   ██ ",
+		"  █████  ",
+	},
+	'7': {
+		" ███████ ",
+		"     ██  ",
+		"    ██   ",
+		"   ██    ",
+		"   ██    ",
+	},
+	'8': {
+		"  █████  ",
+		" ██   ██ ",
+		"  █████  ",
+		" ██   ██ ",
+		"  █████  ",
+	},
+	'9': {
+		"  █████  ",
+		" ██   ██ ",
+		"  ██████ ",
+		"      ██ ",
+		"  █████  ",
+	},
+	'.': {
+		"         ",
+		"         ",
+		"         ",
+		"         ",
+		"   ██    ",
+	},
+	',': {
+		"         ",
+		"         ",
+		"         ",
+		"   ██    ",
+		"  ██     ",
+	},
+	'!': {
+		"   ██    ",
+		"   ██    ",
+		"   ██    ",
+		"         ",
+		"   ██    ",
+	},
+	'?': {
+		"  █████  ",
+		" ██   ██ ",
+		"    ██   ",
+		"         ",
+		"    ██   ",
+	},
+	'\'': {
+		"   ██    ",
+		"  ██     ",
+		"         ",
+		"         ",
+		"         ",
+	},
+	'"': {
+		" ██  ██  ",
+		" ██  ██  ",
+		"         ",
+		"         ",
+		"         ",
+	},
+	'-': {
+		"         ",
+		"         ",
+		" ███████ ",
+		"         ",
+		"         ",
+	},
+	' ': {
+		"         ",
+		"         ",
+		"         ",
+		"         ",
+		"         ",
+	},
+}
+
+const (
+	builtinHeight = 7 // Accent row, five body rows and a row below the baseline
+	builtinWidth  = 9 // All built-in glyphs are exactly 9 columns wide
+)
+
+// Glyphs are drawn as an accent row, the five rows of the letter body and a
+// row for descenders and marks hanging below the baseline
+const (
+	accentRow = 0
+	bodyRow   = 1
+	belowRow  = 6
+)
+
+// Glyphs beyond uppercase ASCII: the rest of ASCII punctuation, Latin-1 and
+// Latin Extended-A letters that have no decomposition, and Greek and Cyrillic
+// capitals that don't share a shape with a Latin letter
+var extendedFont = map[rune][]string{
+	'#': {
+		"  ██ ██  ",
+		" ███████ ",
+		"  ██ ██  ",
+		" ███████ ",
+		"  ██ ██  ",
+	},
+	'$': {
+		"  ██████ ",
+		" ██ ██   ",
+		"  █████  ",
+		"   ██ ██ ",
+		" ██████  ",
+	},
+	'%': {
+		" ██   ██ ",
+		"     ██  ",
+		"   ██    ",
+		"  ██     ",
+		" ██   ██ ",
+	},
+	'&': {
+		"  ████   ",
+		" ██  ██  ",
+		"  ████ █ ",
+		" ██  ██  ",
+		"  ███ ██ ",
+	},
+	'(': {
+		"    ██   ",
+		"   ██    ",
+		"   ██    ",
+		"   ██    ",
+		"    ██   ",
+	},
+	')': {
+		"   ██    ",
+		"    ██   ",
+		"    ██   ",
+		"    ██   ",
+		"   ██    ",
+	},
+	'*': {
+		"         ",
+		" ██ █ ██ ",
+		"  █████  ",
+		" ██ █ ██ ",
+		"         ",
+	},
+	'+': {
+		"         ",
+		"   ██    ",
+		" ██████  ",
+		"   ██    ",
+		"         ",
+	},
+	'/': {
+		"     ██  ",
+		"    ██   ",
+		"   ██    ",
+		"  ██     ",
+		" ██      ",
+	},
+	'\\': {
+		" ██      ",
+		"  ██     ",
+		"   ██    ",
+		"    ██   ",
+		"     ██  ",
+	},
+	':': {
+		"         ",
+		"   ██    ",
+		"         ",
+		"   ██    ",
+		"         ",
+	},
+	';': {
+		"         ",
+		"   ██    ",
+		"         ",
+		"   ██    ",
+		"  ██     ",
+	},
+	'<': {
+		"     ██  ",
+		"   ██    ",
+		" ██      ",
+		"   ██    ",
+		"     ██  ",
+	},
+	'=': {
+		"         ",
+		" ███████ ",
+		"         ",
+		" ███████ ",
+		"         ",
+	},
+	'>': {
+		" ██      ",
+		"   ██    ",
+		"     ██  ",
+		"   ██    ",
+		" ██      ",
+	},
+	'@': {
+		"  █████  ",
+		" ██   ██ ",
+		" ██ ████ ",
+		" ██      ",
+		"  █████  ",
+	},
+	'[': {
+		"  ████   ",
+		"  ██     ",
+		"  ██     ",
+		"  ██     ",
+		"  ████   ",
+	},
+	']': {
+		"  ████   ",
+		"    ██   ",
+		"    ██   ",
+		"    ██   ",
+		"  ████   ",
+	},
+	'^': {
+		"   ███   ",
+		"  ██ ██  ",
+		"         ",
+		"         ",
+		"         ",
+	},
+	'_': {
+		"         ",
+		"         ",
+		"         ",
+		"         ",
+		" ███████ ",
+	},
+	'`': {
+		"  ██     ",
+		"   ██    ",
+		"         ",
+		"         ",
+		"         ",
+	},
+	'{': {
+		"    ███  ",
+		"   ██    ",
+		" ███     ",
+		"   ██    ",
+		"    ███  ",
+	},
+	'|': {
+		"   ██    ",
+		"   ██    ",
+		"   ██    ",
+		"   ██    ",
+		"   ██    ",
+	},
+	'}': {
+		"  ███    ",
+		"    ██   ",
+		"     ███ ",
+		"    ██   ",
+		"  ███    ",
+	},
+	'~': {
+		"         ",
+		"  ██   █ ",
+		" █  ███  ",
+		"         ",
+		"         ",
+	},
+
+	// Latin-1 symbols
+	'¡': {
+		"   ██    ",
+		"         ",
+		"   ██    ",
+		"   ██    ",
+		"   ██    ",
+	},
+	'¢': {
+		"   ██    ",
+		"  █████  ",
+		" ██ ██   ",
+		"  █████  ",
+		"   ██    ",
+	},
+	'£': {
+		"   ████  ",
+		"  ██     ",
+		" █████   ",
+		"  ██     ",
+		" ███████ ",
+	},
+	'¤': {
+		" ██   ██ ",
+		"  █████  ",
+		"  ██ ██  ",
+		"  █████  ",
+		" ██   ██ ",
+	},
+	'¥': {
+		" ██   ██ ",
+		"  ██ ██  ",
+		" ███████ ",
+		"   ██    ",
+		"   ██    ",
+	},
+	'¦': {
+		"   ██    ",
+		"   ██    ",
+		"         ",
+		"   ██    ",
+		"   ██    ",
+	},
+	'§': {
+		"  █████  ",
+		" ███     ",
+		" ██   ██ ",
+		"     ███ ",
+		"  █████  ",
+	},
+	'¨': {
+		" ██   ██ ",
+		"         ",
+		"         ",
+		"         ",
+		"         ",
+	},
+	'©': {
+		" ███████ ",
+		" █ ███ █ ",
+		" █ █   █ ",
+		" █ ███ █ ",
+		" ███████ ",
+	},
+	'ª': {
+		"  ████   ",
+		" ██  ██  ",
+		"  █████  ",
+		"         ",
+		" ██████  ",
+	},
+	'«': {
+		"         ",
+		"  ██ ██  ",
+		" ██ ██   ",
+		"  ██ ██  ",
+		"         ",
+	},
+	'¬': {
+		"         ",
+		" ███████ ",
+		"      ██ ",
+		"         ",
+		"         ",
+	},
+	'®': {
+		" ███████ ",
+		" █ ██  █ ",
+		" █ ███ █ ",
+		" █ █ █ █ ",
+		" ███████ ",
+	},
+	'¯': {
+		" ███████ ",
+		"         ",
+		"         ",
+		"         ",
+		"         ",
+	},
+	'°': {
+		"  ███    ",
+		" ██ ██   ",
+		"  ███    ",
+		"         ",
+		"         ",
+	},
+	'±': {
+		"   ██    ",
+		" ██████  ",
+		"   ██    ",
+		"         ",
+		" ██████  ",
+	},
+	'²': {
+		" ████    ",
+		"    ██   ",
+		"  ██     ",
+		" █████   ",
+		"         ",
+	},
+	'³': {
+		" ████    ",
+		"   ███   ",
+		"    ██   ",
+		" ████    ",
+		"         ",
+	},
+	'´': {
+		"     ██  ",
+		"         ",
+		"         ",
+		"         ",
+		"         ",
+	},
+	'¶': {
+		"  ██████ ",
+		" ████ ██ ",
+		"  ███ ██ ",
+		"   ██ ██ ",
+		"   ██ ██ ",
+	},
+	'·': {
+		"         ",
+		"         ",
+		"   ██    ",
+		"         ",
+		"         ",
+	},
+	'¸': {
+		"         ",
+		"         ",
+		"         ",
+		"   ██    ",
+		"  ██     ",
+	},
+	'¹': {
+		"  ██     ",
+		" ███     ",
+		"  ██     ",
+		" ████    ",
+		"         ",
+	},
+	'º': {
+		"  ████   ",
+		" ██  ██  ",
+		"  ████   ",
+		"         ",
+		" ██████  ",
+	},
+	'»': {
+		"         ",
+		" ██ ██   ",
+		"  ██ ██  ",
+		" ██ ██   ",
+		"         ",
+	},
+	'¼': {
+		" █    █  ",
+		" █   █   ",
+		"    █ █  ",
+		"   █ ███ ",
+		"  █    █ ",
+	},
+	'½': {
+		" █    █  ",
+		" █   █   ",
+		"    █ ██ ",
+		"   █   █ ",
+		"  █   ██ ",
+	},
+	'¾': {
+		" ██   █  ",
+		"  █  █   ",
+		" ██ █ █  ",
+		"   █ ███ ",
+		"  █    █ ",
+	},
+	'¿': {
+		"    ██   ",
+		"         ",
+		"   ██    ",
+		" ██   ██ ",
+		"  █████  ",
+	},
+	'×': {
+		"         ",
+		" ██   ██ ",
+		"   ███   ",
+		" ██   ██ ",
+		"         ",
+	},
+	'÷': {
+		"   ██    ",
+		"         ",
+		" ██████  ",
+		"         ",
+		"   ██    ",
+	},
+
+	// Latin letters without a decomposition
+	'Æ': {
+		"  ██████ ",
+		" ██ ██   ",
+		" ███████ ",
+		" ██ ██   ",
+		" ██ ████ ",
+	},
+	'Ð': {
+		" ██████  ",
+		" ██   ██ ",
+		"████  ██ ",
+		" ██   ██ ",
+		" ██████  ",
+	},
+	'Ø': {
+		"  █████ █",
+		" ██  ███ ",
+		" ██ █ ██ ",
+		" ███  ██ ",
+		"█ █████  ",
+	},
+	'Þ': {
+		" ██      ",
+		" ██████  ",
+		" ██   ██ ",
+		" ██████  ",
+		" ██      ",
+	},
+	'ß': {
+		" ██████  ",
+		" ██   ██ ",
+		" ██ ███  ",
+		" ██   ██ ",
+		" ██ ███  ",
+	},
+	'Ħ': {
+		" ██   ██ ",
+		"█████████",
+		" ███████ ",
+		" ██   ██ ",
+		" ██   ██ ",
+	},
+	'Ĳ': {
+		" ██  ███ ",
+		" ██   ██ ",
+		" ██   ██ ",
+		" ██ █ ██ ",
+		" ██  ██  ",
+	},
+	'Ŀ': {
+		" ██      ",
+		" ██      ",
+		" ██  ██  ",
+		" ██      ",
+		" ███████ ",
+	},
+	'Ł': {
+		" ██      ",
+		" ██ █    ",
+		" ███     ",
+		"███      ",
+		" ███████ ",
+	},
+	'Ŋ': {
+		" ██   ██ ",
+		" ███  ██ ",
+		" ██ █ ██ ",
+		" ██  ███ ",
+		" ██  ██  ",
+	},
+	'Œ': {
+		"  ██████ ",
+		" ██ ██   ",
+		" ██ ████ ",
+		" ██ ██   ",
+		"  ██████ ",
+	},
+	'Ŧ': {
+		" ███████ ",
+		"   ██    ",
+		" ██████  ",
+		"   ██    ",
+		"   ██    ",
+	},
+
+	// Greek
+	'Γ': {
+		" ███████ ",
+		" ██      ",
+		" ██      ",
+		" ██      ",
+		" ██      ",
+	},
+	'Δ': {
+		"   ███   ",
+		"  ██ ██  ",
+		"  ██ ██  ",
+		" ██   ██ ",
+		" ███████ ",
+	},
+	'Θ': {
+		"  █████  ",
+		" ██   ██ ",
+		" ███████ ",
+		" ██   ██ ",
+		"  █████  ",
+	},
+	'Λ': {
+		"   ███   ",
+		"  ██ ██  ",
+		"  ██ ██  ",
+		" ██   ██ ",
+		" ██   ██ ",
+	},
+	'Ξ': {
+		" ███████ ",
+		"         ",
+		"  █████  ",
+		"         ",
+		" ███████ ",
+	},
+	'Π': {
+		" ███████ ",
+		" ██   ██ ",
+		" ██   ██ ",
+		" ██   ██ ",
+		" ██   ██ ",
+	},
+	'Σ': {
+		" ███████ ",
+		"  ██     ",
+		"   ██    ",
+		"  ██     ",
+		" ███████ ",
+	},
+	'Φ': {
+		"    █    ",
+		"  █████  ",
+		" ██ █ ██ ",
+		"  █████  ",
+		"    █    ",
+	},
+	'Ψ': {
+		" ██ █ ██ ",
+		" ██ █ ██ ",
+		"  █████  ",
+		"    █    ",
+		"    █    ",
+	},
+	'Ω': {
+		"  █████  ",
+		" ██   ██ ",
+		" ██   ██ ",
+		"  ██ ██  ",
+		" ███ ███ ",
+	},
+
+	// Cyrillic
+	'Б': {
+		" ███████ ",
+		" ██      ",
+		" ██████  ",
+		" ██   ██ ",
+		" ██████  ",
+	},
+	'Д': {
+		"   ████  ",
+		"  ██ ██  ",
+		"  ██ ██  ",
+		" ███████ ",
+		" ██   ██ ",
+	},
+	'Ж': {
+		" █  █  █ ",
+		"  █ █ █  ",
+		"   ███   ",
+		"  █ █ █  ",
+		" █  █  █ ",
+	},
+	'З': {
+		" ██████  ",
+		"      ██ ",
+		"   ████  ",
+		"      ██ ",
+		" ██████  ",
+	},
+	'И': {
+		" ██   ██ ",
+		" ██  ███ ",
+		" ██ █ ██ ",
+		" ███  ██ ",
+		" ██   ██ ",
+	},
+	'Л': {
+		"   █████ ",
+		"  ██  ██ ",
+		"  ██  ██ ",
+		"  ██  ██ ",
+		" ██   ██ ",
+	},
+	'У': {
+		" ██   ██ ",
+		" ██   ██ ",
+		"  ██████ ",
+		"      ██ ",
+		"  █████  ",
+	},
+	'Ц': {
+		" ██  ██  ",
+		" ██  ██  ",
+		" ██  ██  ",
+		" ███████ ",
+		"      ██ ",
+	},
+	'Ч': {
+		" ██   ██ ",
+		" ██   ██ ",
+		"  ██████ ",
+		"      ██ ",
+		"      ██ ",
+	},
+	'Ш': {
+		" ██ █ ██ ",
+		" ██ █ ██ ",
+		" ██ █ ██ ",
+		" ██ █ ██ ",
+		" ███████ ",
+	},
+	'Щ': {
+		" █  █  █ ",
+		" █  █  █ ",
+		" █  █  █ ",
+		" ████████",
+		"        █",
+	},
+	'Ъ': {
+		" ███     ",
+		"  ██     ",
+		"  █████  ",
+		"  ██  ██ ",
+		"  █████  ",
+	},
+	'Ы': {
+		" ██    ██",
+		" ██    ██",
+		" █████ ██",
+		" ██  █ ██",
+		" █████ ██",
+	},
+	'Ь': {
+		" ██      ",
+		" ██      ",
+		" ██████  ",
+		" ██   ██ ",
+		" ██████  ",
+	},
+	'Э': {
+		"  █████  ",
+		"      ██ ",
+		"   █████ ",
+		"      ██ ",
+		"  █████  ",
+	},
+	'Ю': {
+		" █  ████ ",
+		" █ █   █ ",
+		" ████  █ ",
+		" █ █   █ ",
+		" █  ████ ",
+	},
+	'Я': {
+		"  ██████ ",
+		" ██   ██ ",
+		"  ██████ ",
+		"  ██  ██ ",
+		" ██   ██ ",
+	},
+	'Є': {
+		"  █████  ",
+		" ██      ",
+		" █████   ",
+		" ██      ",
+		"  █████  ",
+	},
+	'Ђ': {
+		" ██████  ",
+		"   ██    ",
+		"   █████ ",
+		"   ██  ██",
+		"   ██ ██ ",
+	},
+	'Ћ': {
+		" ██████  ",
+		"   ██    ",
+		"   █████ ",
+		"   ██  ██",
+		"   ██  ██",
+	},
+	'Љ': {
+		"  ███    ",
+		"  █ █    ",
+		"  █ ████ ",
+		"  █ █  █ ",
+		" █  ████ ",
+	},
+	'Њ': {
+		" █  █    ",
+		" █  █    ",
+		" ██████  ",
+		" █  █  █ ",
+		" █  ████ ",
+	},
+	'Џ': {
+		" ██   ██ ",
+		" ██   ██ ",
+		" ██   ██ ",
+		" ███████ ",
+		"    █    ",
+	},
+	'Ґ': {
+		"      ██ ",
+		" ███████ ",
+		" ██      ",
+		" ██      ",
+		" ██      ",
+	},
+
+	// Typographic punctuation common in books
+	'„': {
+		"         ",
+		"         ",
+		"         ",
+		" ██  ██  ",
+		" ██  ██  ",
+	},
+	'—': {
+		"         ",
+		"         ",
+		"█████████",
+		"         ",
+		"         ",
+	},
+	'…': {
+		"         ",
+		"         ",
+		"         ",
+		"         ",
+		"██ ██ ██ ",
+	},
+	'•': {
+		"         ",
+		"   ███   ",
+		"  █████  ",
+		"   ███   ",
+		"         ",
+	},
+	'‹': {
+		"         ",
+		"   ██    ",
+		"  ██     ",
+		"   ██    ",
+		"         ",
+	},
+	'›': {
+		"         ",
+		"  ██     ",
+		"   ██    ",
+		"  ██     ",
+		"         ",
+	},
+	'†': {
+		"   ██    ",
+		" ██████  ",
+		"   ██    ",
+		"   ██    ",
+		"   ██    ",
+	},
+	'‡': {
+		"   ██    ",
+		" ██████  ",
+		"   ██    ",
+		" ██████  ",
+		"   ██    ",
+	},
+	'€': {
+		"   █████ ",
+		"  ██     ",
+		"██████   ",
+		"  ██     ",
+		"   █████ ",
+	},
+}
+
+// Lowercase glyphs: the five body rows plus a descender row below the
+// baseline. Letters without an ascender leave the first row empty.
+var lowercaseFont = map[rune][]string{
+	'a': {
+		"         ",
+		"  ██████ ",
+		" ██   ██ ",
+		" ██   ██ ",
+		"  ██████ ",
+		"         ",
+	},
+	'b': {
+		" ██      ",
+		" ██████  ",
+		" ██   ██ ",
+		" ██   ██ ",
+		" ██████  ",
+		"         ",
+	},
+	'c': {
+		"         ",
+		"  ██████ ",
+		" ██      ",
+		" ██      ",
+		"  ██████ ",
+		"         ",
+	},
+	'd': {
+		"      ██ ",
+		"  ██████ ",
+		" ██   ██ ",
+		" ██   ██ ",
+		"  ██████ ",
+		"         ",
+	},
+	'e': {
+		"         ",
+		"  █████  ",
+		" ███████ ",
+		" ██      ",
+		"  ██████ ",
+		"         ",
+	},
+	'f': {
+		"   ████  ",
+		"  ██     ",
+		" █████   ",
+		"  ██     ",
+		"  ██     ",
+		"         ",
+	},
+	'g': {
+		"         ",
+		"  ██████ ",
+		" ██   ██ ",
+		"  ██████ ",
+		"      ██ ",
+		"  █████  ",
+	},
+	'h': {
+		" ██      ",
+		" ██████  ",
+		" ██   ██ ",
+		" ██   ██ ",
+		" ██   ██ ",
+		"         ",
+	},
+	'i': {
+		"   ██    ",
+		"         ",
+		"  ███    ",
+		"   ██    ",
+		"  ████   ",
+		"         ",
+	},
+	'j': {
+		"     ██  ",
+		"         ",
+		"    ███  ",
+		"     ██  ",
+		" ██  ██  ",
+		"  ████   ",
+	},
+	'k': {
+		" ██      ",
+		" ██  ██  ",
+		" ████    ",
+		" ██ ██   ",
+		" ██  ██  ",
+		"         ",
+	},
+	'l': {
+		"  ███    ",
+		"   ██    ",
+		"   ██    ",
+		"   ██    ",
+		"   ████  ",
+		"         ",
+	},
+	'm': {
+		"         ",
+		" ███ ██  ",
+		" ██ █ ██ ",
+		" ██ █ ██ ",
+		" ██ █ ██ ",
+		"         ",
+	},
+	'n': {
+		"         ",
+		" ██████  ",
+		" ██   ██ ",
+		" ██   ██ ",
+		" ██   ██ ",
+		"         ",
+	},
+	'o': {
+		"         ",
+		"  █████  ",
+		" ██   ██ ",
+		" ██   ██ ",
+		"  █████  ",
+		"         ",
+	},
+	'p': {
+		"         ",
+		" ██████  ",
+		" ██   ██ ",
+		" ██████  ",
+		" ██      ",
+		" ██      ",
+	},
+	'q': {
+		"         ",
+		"  ██████ ",
+		" ██   ██ ",
+		"  ██████ ",
+		"      ██ ",
+		"      ██ ",
+	},
+	'r': {
+		"         ",
+		" ██ ███  ",
+		" ███     ",
+		" ██      ",
+		" ██      ",
+		"         ",
+	},
+	's': {
+		"         ",
+		"  ██████ ",
+		" ███     ",
+		"     ███ ",
+		" ██████  ",
+		"         ",
+	},
+	't': {
+		"  ██     ",
+		" █████   ",
+		"  ██     ",
+		"  ██     ",
+		"   ████  ",
+		"         ",
+	},
+	'u': {
+		"         ",
+		" ██   ██ ",
+		" ██   ██ ",
+		" ██   ██ ",
+		"  ██████ ",
+		"         ",
+	},
+	'v': {
+		"         ",
+		" ██   ██ ",
+		" ██   ██ ",
+		"  ██ ██  ",
+		"   ███   ",
+		"         ",
+	},
+	'w': {
+		"         ",
+		" ██ █ ██ ",
+		" ██ █ ██ ",
+		" ██ █ ██ ",
+		"  ██ ██  ",
+		"         ",
+	},
+	'x': {
+		"         ",
+		" ██   ██ ",
+		"   ███   ",
+		"   ███   ",
+		" ██   ██ ",
+		"         ",
+	},
+	'y': {
+		"         ",
+		" ██   ██ ",
+		" ██   ██ ",
+		"  ██████ ",
+		"      ██ ",
+		"  █████  ",
+	},
+	'z': {
+		"         ",
+		" ███████ ",
+		"    ██   ",
+		"  ██     ",
+		" ███████ ",
+		"         ",
+	},
+
+	// Dotless forms, used under accents
+	'ı': {
+		"         ",
+		"         ",
+		"  ███    ",
+		"   ██    ",
+		"  ████   ",
+		"         ",
+	},
+	'ȷ': {
+		"         ",
+		"         ",
+		"    ███  ",
+		"     ██  ",
+		" ██  ██  ",
+		"  ████   ",
+	},
+
+	// Latin-1 and Latin Extended-A letters without a decomposition
+	'æ': {
+		"         ",
+		"  ██ ███ ",
+		" ███████ ",
+		"█  ██    ",
+		" ███ ███ ",
+		"         ",
+	},
+	'ð': {
+		"  ████   ",
+		"     ██  ",
+		"  ██████ ",
+		" ██   ██ ",
+		"  █████  ",
+		"         ",
+	},
+	'ø': {
+		"         ",
+		"  █████ █",
+		" ██  ███ ",
+		" ███  ██ ",
+		"█ █████  ",
+		"         ",
+	},
+	'þ': {
+		" ██      ",
+		" ██████  ",
+		" ██   ██ ",
+		" ██████  ",
+		" ██      ",
+		" ██      ",
+	},
+	'œ': {
+		"         ",
+		"  ██ ██  ",
+		" ██ ████ ",
+		" ██ ██   ",
+		"  ██ ███ ",
+		"         ",
+	},
+	'đ': {
+		"    █████",
+		"  ██████ ",
+		" ██   ██ ",
+		" ██   ██ ",
+		"  ██████ ",
+		"         ",
+	},
+	'ħ': {
+		"████     ",
+		" ██████  ",
+		" ██   ██ ",
+		" ██   ██ ",
+		" ██   ██ ",
+		"         ",
+	},
+	'ĳ': {
+		" ██  ██  ",
+		"         ",
+		" ██  ██  ",
+		" ██  ██  ",
+		" ██  ██  ",
+		"   ███   ",
+	},
+	'ŀ': {
+		" ███     ",
+		"  ██     ",
+		"  ██  ██ ",
+		"  ██     ",
+		"  ████   ",
+		"         ",
+	},
+	'ł': {
+		"  ███    ",
+		"   ██    ",
+		"   ███   ",
+		"  ███    ",
+		"   ████  ",
+		"         ",
+	},
+	'ŋ': {
+		"         ",
+		" ██████  ",
+		" ██   ██ ",
+		" ██   ██ ",
+		" ██   ██ ",
+		"    ███  ",
+	},
+	'ŧ': {
+		"  ██     ",
+		" █████   ",
+		" █████   ",
+		"  ██     ",
+		"   ████  ",
+		"         ",
+	},
+}
+
+// Characters drawn with the glyph of another character that has the same shape
+var glyphAliases = map[rune]rune{
+	// Greek capitals
+	'Α': 'A', 'Β': 'B', 'Ε': 'E', 'Ζ': 'Z', 'Η': 'H', 'Ι': 'I', 'Κ': 'K',
+	'Μ': 'M', 'Ν': 'N', 'Ο': 'O', 'Ρ': 'P', 'Τ': 'T', 'Υ': 'Y', 'Χ': 'X',
+	'ϒ': 'Y',
+
+	// Cyrillic capitals
+	'А': 'A', 'В': 'B', 'Е': 'E', 'К': 'K', 'М': 'M', 'Н': 'H', 'О': 'O',
+	'Р': 'P', 'С': 'C', 'Т': 'T', 'Х': 'X', 'Ѕ': 'S', 'І': 'I', 'Ј': 'J',
+	'Г': 'Γ', 'П': 'Π', 'Ф': 'Φ',
+
+	// Latin
+	'Đ': 'Ð', 'ĸ': 'K', 'ŉ': 'n', 'ſ': 's', 'ẞ': 'ß',
+
+	// Typographic punctuation
+	'‘': '`', '’': '\'', '‚': ',', '‛': '`', '′': '\'',
+	'“': '"', '”': '"', '‟': '"', '″': '"',
+	'‐': '-', '‑': '-', '‒': '-', '–': '-', '−': '-', '―': '—',
+	'⁄': '/', '‧': '·', '∙': '·',
+}
+
+// Combining marks drawn in the accent row above a letter
+var marksAbove = map[rune]string{
+	'\u0300': "  ██     ", // Grave
+	'\u0301': "     ██  ", // Acute
+	'\u0302': "   ███   ", // Circumflex
+	'\u0303': "  ███ ██ ", // Tilde
+	'\u0304': " ███████ ", // Macron
+	'\u0306': "  █   █  ", // Breve
+	'\u0307': "   ██    ", // Dot above
+	'\u0308': " ██   ██ ", // Diaeresis
+	'\u030a': "   █ █   ", // Ring above
+	'\u030b': "   ██ ██ ", // Double acute
+	'\u030c': "  ██ ██  ", // Caron
+}
+
+// Combining marks drawn in the row below the baseline
+var marksBelow = map[rune]string{
+	'\u0326': "   ██    ", // Comma below
+	'\u0327': "   ███   ", // Cedilla
+	'\u0328': "     ██  ", // Ogonek
+}
+
+// Shown for characters the font can't draw, so they don't vanish
+var placeholderGlyph = []string{
+	" ███████ ",
+	" ██   ██ ",
+	" █ █ █ █ ",
+	" ██   ██ ",
+	" ███████ ",
+}
+
+// bodyGlyph returns the body rows for a character without marks: five for
+// capitals and symbols, six for lowercase letters with their descender row.
+// Lowercase letters without a glyph of their own are drawn as capitals.
+func bodyGlyph(r rune) ([]string, bool) {
+	if alias, ok := glyphAliases[r]; ok {
+		r = alias
+	}
+	if g, ok := font[r]; ok {
+		return g, true
+	}
+	if g, ok := lowercaseFont[r]; ok {
+		return g, true
+	}
+	if g, ok := extendedFont[r]; ok {
+		return g, true
+	}
+	if upper := unicode.ToUpper(r); upper != r {
+		return bodyGlyph(upper)
+	}
+	return nil, false
+}
+
+// Accents replace the dot of i and j
+var dotlessForms = map[rune]rune{'i': 'ı', 'j': 'ȷ'}
+
+// glyph returns the builtinHeight rows used to draw r. Accented letters are
+// composed from the base letter and its combining marks.
+func glyph(r rune) []string {
+	body, ok := bodyGlyph(r)
+	var marks []rune
+	if !ok {
+		decomposed := []rune(norm.NFD.String(string(r)))
+		base := decomposed[0]
+		if dotless, ok := dotlessForms[base]; ok && hasMarkAbove(decomposed[1:]) {
+			base = dotless
+		}
+		if body, ok = bodyGlyph(base); ok {
+			marks = decomposed[1:]
+		} else {
+			body = placeholderGlyph
+		}
+	}
+
+	blank := strings.Repeat(" ", builtinWidth)
+	rows := make([]string, builtinHeight)
+	for i := range rows {
+		rows[i] = blank
+	}
+	copy(rows[bodyRow:], body)
+
+	// Accents sit just above the letter: in the accent row over capitals
+	// and ascenders, in the empty top body row over short lowercase letters
+	above := accentRow
+	if rows[bodyRow] == blank {
+		above = bodyRow
+	}
+	for _, m := range marks {
+		if mark, ok := marksAbove[m]; ok {
+			rows[above] = overlayRow(rows[above], mark)
+		} else if mark, ok := marksBelow[m]; ok {
+			rows[belowRow] = overlayRow(rows[belowRow], mark)
+		}
+	}
+	return rows
+}
+
+func hasMarkAbove(marks []rune) bool {
+	for _, m := range marks {
+		if _, ok := marksAbove[m]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// overlayRow draws the filled cells of top over row
+func overlayRow(row, top string) string {
+	out := []rune(row)
+	for i, r := range []rune(top) {
+		if r != ' ' && i < len(out) {
+			out[i] = r
+		}
+	}
+	return string(out)
+}
+
+// displayRunes returns the characters of word as they are drawn: combining
+// sequences are composed and invisible format characters such as soft
+// hyphens are dropped
+func displayRunes(word string) []rune {
+	word = norm.NFC.String(word)
+	runes := make([]rune, 0, len(word))
+	for _, r := range word {
+		// Marks that didn't compose with their letter can't be drawn on their own
+		if unicode.In(r, unicode.Cf, unicode.Mn, unicode.Me) {
+			continue
+		}
+		runes = append(runes, r)
+	}
+	return runes
+}
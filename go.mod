@@ -4,7 +4,11 @@ go 1.25.4
 
 require (
 	github.com/go-shiori/go-readability v0.0.0-20251205110129-5db1dc9836f0
+	github.com/mattn/go-runewidth v0.0.16
+	golang.org/x/image v0.25.0
+	golang.org/x/net v0.35.0
 	golang.org/x/term v0.39.0
+	golang.org/x/text v0.23.0
 )
 
 require (
@@ -12,7 +16,6 @@ require (
 	github.com/araddon/dateparse v0.0.0-20210429162001-6b43995a97de // indirect
 	github.com/go-shiori/dom v0.0.0-20230515143342-73569d674e1c // indirect
 	github.com/gogs/chardet v0.0.0-20211120154057-b7413eaefb8f // indirect
-	golang.org/x/net v0.35.0 // indirect
+	github.com/rivo/uniseg v0.2.0 // indirect
 	golang.org/x/sys v0.40.0 // indirect
-	golang.org/x/text v0.22.0 // indirect
 )
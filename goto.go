@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// resolvePosition finds the word a go-to address points at, for the :
+// command and -start. An address is a word number ("1234"), a percentage
+// ("37%"), a page ("p12"), a chapter ("ch5", counted as chapter navigation
+// counts them) or a number of words forward or back from word from ("+200",
+// "-200"). Numbers count from 1, and positions past the end go to the last
+// word.
+func resolvePosition(spec string, doc *document, from int) (int, error) {
+	spec = strings.ToLower(strings.TrimSpace(spec))
+	last := len(doc.Tokens) - 1
+	var to int
+	switch {
+	case spec == "":
+		return 0, errors.New("empty position")
+	case strings.HasSuffix(spec, "%"):
+		percent, err := strconv.ParseFloat(strings.TrimSuffix(spec, "%"), 64)
+		if err != nil || percent < 0 || percent > 100 {
+			return 0, fmt.Errorf("invalid percentage %q", spec)
+		}
+		to = int(float64(len(doc.Tokens)) * percent / 100)
+	case spec[0] == '+' || spec[0] == '-':
+		n, err := strconv.Atoi(spec)
+		if err != nil {
+			return 0, fmt.Errorf("invalid word count %q", spec)
+		}
+		to = from + n
+	case strings.HasPrefix(spec, "ch"):
+		n, err := strconv.Atoi(spec[2:])
+		if err != nil || n < 1 {
+			return 0, fmt.Errorf("invalid chapter %q", spec)
+		}
+		chapters := doc.chapters()
+		if len(chapters) == 0 {
+			return 0, errors.New("the text has no chapters")
+		}
+		if n > len(chapters) {
+			return 0, fmt.Errorf("no chapter %d (the text has %d)", n, len(chapters))
+		}
+		to = doc.Sections[chapters[n-1]].Start
+	case strings.HasPrefix(spec, "p"):
+		n, err := strconv.Atoi(spec[1:])
+		if err != nil || n < 1 {
+			return 0, fmt.Errorf("invalid page %q", spec)
+		}
+		if len(doc.Pages) == 0 {
+			return 0, errors.New("the text has no pages")
+		}
+		if n > len(doc.Pages) {
+			return 0, fmt.Errorf("no page %d (the text has %d)", n, len(doc.Pages))
+		}
+		to = doc.Pages[n-1]
+	default:
+		n, err := strconv.Atoi(spec)
+		if err != nil || n < 1 {
+			return 0, fmt.Errorf("invalid position %q (want a word number, 37%%, p12, ch5, +200 or -200)", spec)
+		}
+		to = n - 1
+	}
+	return min(max(to, 0), last), nil
+}
+
+// commandLine is the : prompt, shared by the input goroutine and the render
+// loop like searchState
+type commandLine struct {
+	mu      sync.Mutex
+	editing bool // The prompt is open
+	text    string
+	message string // Why the last command failed
+}
+
+// commandView is a copy of the command line for drawing a frame
+type commandView struct {
+	Editing bool
+	Text    string
+	Message string
+}
+
+func (c *commandLine) view() commandView {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return commandView{c.editing, c.text, c.message}
+}
+
+// prompt is the command line as shown at the bottom of the screen
+func (v commandView) prompt() string {
+	return ":" + v.Text + "█"
+}
+
+// edit changes the command line with fn, which returns false to close it
+func (c *commandLine) edit(fn func(c *commandLine) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.editing = fn(c)
+}
+
+// clear forgets why the last command failed
+func (c *commandLine) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.message = ""
+}
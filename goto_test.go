@@ -0,0 +1,36 @@
+package main
+
+import "testing"
+
+func TestResolvePosition(t *testing.T) {
+	doc := buildDocument(readMarkdown(testReadme, readOptions{}))
+	last := len(doc.Tokens) - 1
+	tests := []struct {
+		spec string
+		from int
+		want int
+	}{
+		{"1", 0, 0},
+		{"5", 0, 4},
+		{"99999", 0, last},
+		{"0%", 0, 0},
+		{"100%", 0, last},
+		{"+3", 2, 5},
+		{"-10", 2, 0},
+		{"ch1", 0, wordIndex(t, doc, "Install")},
+		{"ch2", 0, wordIndex(t, doc, "Usage")},
+		{" CH3 ", 0, wordIndex(t, doc, "License")},
+	}
+	for _, tt := range tests {
+		got, err := resolvePosition(tt.spec, doc, tt.from)
+		if err != nil || got != tt.want {
+			t.Errorf("resolvePosition(%q, %d) = %d, %v, want %d", tt.spec, tt.from, got, err, tt.want)
+		}
+	}
+
+	for _, spec := range []string{"", "ch0", "ch4", "chx", "p1", "101%", "x"} {
+		if got, err := resolvePosition(spec, doc, 0); err == nil {
+			t.Errorf("resolvePosition(%q) = %d, want an error", spec, got)
+		}
+	}
+}
@@ -0,0 +1,479 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	xfont "golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Render modes that draw the word as an image through a terminal graphics
+// protocol. "image" picks whichever one the terminal supports.
+var graphicsProtocols = []string{"kitty", "iterm2", "sixel"}
+
+// Focal colors as RGB, for modes that draw pixels instead of ANSI text
+var focalRGB = map[string]color.RGBA{
+	"black":   {0x00, 0x00, 0x00, 0xff},
+	"red":     {0xe0, 0x30, 0x30, 0xff},
+	"green":   {0x30, 0xc0, 0x40, 0xff},
+	"yellow":  {0xe0, 0xc0, 0x20, 0xff},
+	"blue":    {0x40, 0x70, 0xe0, 0xff},
+	"magenta": {0xd0, 0x40, 0xd0, 0xff},
+	"cyan":    {0x30, 0xc0, 0xd0, 0xff},
+	"white":   {0xff, 0xff, 0xff, 0xff},
+}
+
+// graphicsTerm is what the terminal reported about itself
+type graphicsTerm struct {
+	Protocol   string // One of graphicsProtocols, or "" if none is supported
+	CellWidth  int    // Size of a character cell in pixels
+	CellHeight int
+	Foreground color.RGBA
+	Background color.RGBA
+	FocalColor color.RGBA
+	last       graphicsFrame // Most recent frame, reused while paused
+	faces      map[int]xfont.Face
+	font       *opentype.Font
+}
+
+type graphicsFrame struct {
+	word                  string
+	termWidth, termHeight int
+	lines                 []string
+}
+
+var (
+	kittyReply    = regexp.MustCompile(`\x1b_Gi=31;OK`)
+	da1Reply      = regexp.MustCompile(`\x1b\[\?([\d;]*)c`)
+	versionReply  = regexp.MustCompile(`\x1bP>\|([^\x1b]*)`)
+	cellSizeReply = regexp.MustCompile(`\x1b\[6;(\d+);(\d+)t`)
+	colorReply    = regexp.MustCompile(`\x1b\]1([01]);rgb:([0-9a-fA-F]+)/([0-9a-fA-F]+)/([0-9a-fA-F]+)`)
+)
+
+// detectGraphics asks the terminal which image protocols it supports, its
+// cell size in pixels and its colors. tty must be in raw mode. The device
+// attributes query goes last since every terminal answers it, so its reply
+// marks the end of the others.
+func detectGraphics(tty *os.File) *graphicsTerm {
+	g := &graphicsTerm{
+		CellWidth:  10,
+		CellHeight: 20,
+		Foreground: color.RGBA{0xdd, 0xdd, 0xdd, 0xff},
+		Background: color.RGBA{0x00, 0x00, 0x00, 0xff},
+	}
+
+	var reply []byte
+	if tty.SetReadDeadline(time.Now().Add(300*time.Millisecond)) == nil {
+		fmt.Fprint(tty, "\x1b_Gi=31,s=1,v=1,a=q,t=d,f=24;AAAA\x1b\\", // Kitty graphics query
+			"\x1b[>0q",        // Terminal name and version
+			"\x1b[16t",        // Cell size in pixels
+			"\x1b]10;?\x1b\\", // Foreground color
+			"\x1b]11;?\x1b\\", // Background color
+			"\x1b[c")          // Primary device attributes
+		buf := make([]byte, 256)
+		for !da1Reply.Match(reply) {
+			n, err := tty.Read(buf)
+			reply = append(reply, buf[:n]...)
+			if err != nil {
+				break
+			}
+		}
+
+		// Some terminals answer out of order, so more replies can follow the
+		// device attributes. Read until the terminal goes quiet so none are
+		// taken as key presses.
+		for limit := time.Now().Add(time.Second); time.Now().Before(limit); {
+			tty.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+			n, err := tty.Read(buf)
+			reply = append(reply, buf[:n]...)
+			if err != nil {
+				break
+			}
+		}
+		tty.SetReadDeadline(time.Time{})
+	}
+
+	if m := cellSizeReply.FindSubmatch(reply); m != nil {
+		h, _ := strconv.Atoi(string(m[1]))
+		w, _ := strconv.Atoi(string(m[2]))
+		if w > 0 && h > 0 {
+			g.CellWidth, g.CellHeight = w, h
+		}
+	}
+	for _, m := range colorReply.FindAllSubmatch(reply, -1) {
+		c := color.RGBA{xColor(m[2]), xColor(m[3]), xColor(m[4]), 0xff}
+		if string(m[1]) == "0" {
+			g.Foreground = c
+		} else {
+			g.Background = c
+		}
+	}
+
+	version := ""
+	if m := versionReply.FindSubmatch(reply); m != nil {
+		version = string(m[1])
+	}
+	switch {
+	case kittyReply.Match(reply), os.Getenv("KITTY_WINDOW_ID") != "":
+		g.Protocol = "kitty"
+	case strings.Contains(version, "iTerm2"), strings.Contains(version, "WezTerm"),
+		os.Getenv("TERM_PROGRAM") == "iTerm.app", os.Getenv("TERM_PROGRAM") == "WezTerm":
+		g.Protocol = "iterm2"
+	default:
+		if m := da1Reply.FindSubmatch(reply); m != nil && slices.Contains(strings.Split(string(m[1]), ";"), "4") {
+			g.Protocol = "sixel"
+		}
+	}
+	return g
+}
+
+// xColor converts one X11 color component of 1-4 hex digits to 8 bits
+func xColor(hex []byte) uint8 {
+	v, _ := strconv.ParseUint(string(hex), 16, 32)
+	maxVal := uint64(1)<<(4*len(hex)) - 1
+	return uint8(v * 255 / maxVal)
+}
+
+// face returns the Go Regular font at a size in pixels
+func (g *graphicsTerm) face(size int) xfont.Face {
+	if g.font == nil {
+		g.font, _ = opentype.Parse(goregular.TTF)
+		g.faces = make(map[int]xfont.Face)
+	}
+	if f, ok := g.faces[size]; ok {
+		return f
+	}
+	f, err := opentype.NewFace(g.font, &opentype.FaceOptions{Size: float64(size), DPI: 72, Hinting: xfont.HintingFull})
+	if err != nil {
+		return nil
+	}
+	g.faces[size] = f
+	return f
+}
+
+// renderImageWord draws word as an image and returns the lines to
+// print: blank lines down to the image, the image itself positioned so the
+// ORP letter is centered, then blank lines for the rows the image covers.
+func renderImageWord(word string, lay layout, opts renderOptions) []string {
+	g := opts.Graphics
+	termWidth, termHeight := lay.Width, lay.Height
+	if g.last.lines != nil && g.last.word == word && g.last.termWidth == termWidth && g.last.termHeight == termHeight {
+		return g.last.lines
+	}
+
+	runes := displayRunes(word)
+	orpIndex := calculateORP(len(runes))
+
+	var img *image.RGBA
+	var orpCenter int
+	if trueType, upper := imageFont(opts.Face); trueType {
+		if upper {
+			for i, r := range runes {
+				runes[i] = unicode.ToUpper(r)
+			}
+		}
+		img, orpCenter = g.drawTrueType(runes, orpIndex, lay, opts.Focal)
+	} else {
+		img, orpCenter = g.drawBitmap(runes, orpIndex, lay, opts)
+	}
+	if img == nil {
+		return nil
+	}
+	width := img.Bounds().Dx()
+	rows := img.Bounds().Dy() / g.CellHeight
+
+	cols := (width + g.CellWidth - 1) / g.CellWidth
+	padding := (termWidth - cols) / 2
+	if opts.Focal {
+		padding = termWidth/2 - orpCenter/g.CellWidth
+	}
+	padding = max(min(padding, termWidth-cols), 0)
+
+	var encoded string
+	switch opts.Mode {
+	case "kitty":
+		encoded = kittyImage(img)
+	case "iterm2":
+		encoded = itermImage(img)
+	case "sixel":
+		encoded = sixelImage(img, g.Background)
+	}
+
+	topPadding := max((termHeight-rows)/2, 0)
+	lines := make([]string, topPadding, topPadding+rows)
+	// Save and restore the cursor so it doesn't matter where each protocol
+	// leaves it after the image
+	lines = append(lines, strings.Repeat(" ", padding)+"\x1b7"+encoded+"\x1b8")
+	for range rows - 1 {
+		lines = append(lines, "")
+	}
+
+	g.last = graphicsFrame{word, termWidth, termHeight, lines}
+	return lines
+}
+
+// imageFont says how image modes draw text in face: with the TrueType font
+// unless -font chose another, and in capitals with -uppercase
+func imageFont(face fontFace) (trueType, upper bool) {
+	if u, ok := face.(uppercaseFont); ok {
+		face, upper = u.fontFace, true
+	}
+	_, trueType = face.(builtinFont)
+	return trueType, upper
+}
+
+// drawTrueType draws runes with the TrueType font, returning the image,
+// whole cells high, and the x of the center of the ORP letter
+func (g *graphicsTerm) drawTrueType(runes []rune, orpIndex int, lay layout, focal bool) (*image.RGBA, int) {
+	// Size the font like the block renderer: half the screen height, or
+	// smaller so the longest word fits
+	maxTextWidth := (lay.Width - 4) * g.CellWidth
+	maxTextHeight := float64(lay.Height-4) * targetHeightPercent * float64(g.CellHeight)
+	const reference = 100
+	refFace := g.face(reference)
+	if refFace == nil {
+		return nil, 0
+	}
+	refLine := float64(refFace.Metrics().Height.Ceil()) / reference
+	refAdvance, _ := refFace.GlyphAdvance('o')
+	size := min(maxTextHeight/refLine, float64(maxTextWidth)/(float64(lay.MaxWordLen)*float64(refAdvance.Ceil())/reference))
+	if wordWidth := xfont.MeasureString(refFace, string(runes)).Ceil(); wordWidth > 0 {
+		size = min(size, float64(maxTextWidth)*reference/float64(wordWidth))
+	}
+	face := g.face(max(int(size), g.CellHeight))
+	if face == nil {
+		return nil, 0
+	}
+
+	metrics := face.Metrics()
+	rows := max((metrics.Ascent+metrics.Descent).Ceil()/g.CellHeight+1, 1)
+	height := rows * g.CellHeight
+	width := xfont.MeasureString(face, string(runes)).Ceil() + 2
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	// Draw one rune at a time so the ORP letter gets its own color
+	baseline := (height-(metrics.Ascent+metrics.Descent).Ceil())/2 + metrics.Ascent.Ceil()
+	d := xfont.Drawer{Dst: img, Face: face, Dot: fixed.P(1, baseline)}
+	orpCenter := 0
+	for i, r := range runes {
+		if i > 0 {
+			d.Dot.X += face.Kern(runes[i-1], r)
+		}
+		fg := g.Foreground
+		if i == orpIndex && focal {
+			fg = g.FocalColor
+		}
+		start := d.Dot.X
+		d.Src = image.NewUniform(fg)
+		d.DrawString(string(r))
+		if i == orpIndex {
+			orpCenter = (start + d.Dot.X).Round() / 2
+		}
+	}
+	return img, orpCenter
+}
+
+// drawBitmap draws runes in the font chosen with -font, each of its pixels
+// a square sized so words fit like the TrueType text
+func (g *graphicsTerm) drawBitmap(runes []rune, orpIndex int, lay layout, opts renderOptions) (*image.RGBA, int) {
+	lines, spans := opts.Face.Render(runes)
+	if len(lines) == 0 || len(lines[0]) == 0 {
+		return nil, 0
+	}
+	srcWidth, srcHeight := len([]rune(lines[0])), len(lines)
+
+	maxTextWidth := float64((lay.Width - 4) * g.CellWidth)
+	maxTextHeight := float64(lay.Height-4) * targetHeightPercent * float64(g.CellHeight)
+	px := min(maxTextHeight/float64(srcHeight),
+		maxTextWidth/float64(lay.MaxWordLen*opts.Face.CellWidth()),
+		maxTextWidth/float64(srcWidth))
+	px = max(px, 1)
+
+	rows := max(int(float64(srcHeight)*px)/g.CellHeight+1, 1)
+	height := rows * g.CellHeight
+	width := int(float64(srcWidth)*px) + 2
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	top := (height - int(float64(srcHeight)*px)) / 2
+
+	var orp glyphSpan
+	if orpIndex < len(spans) {
+		orp = spans[orpIndex]
+	}
+	for y, line := range lines {
+		for x, r := range []rune(line) {
+			if r == ' ' {
+				continue
+			}
+			fg := g.Foreground
+			if opts.Focal && x >= orp.Start && x < orp.End {
+				fg = g.FocalColor
+			}
+			rect := image.Rect(1+int(float64(x)*px), top+int(float64(y)*px), 1+int(float64(x+1)*px), top+int(float64(y+1)*px))
+			draw.Draw(img, rect, image.NewUniform(fg), image.Point{}, draw.Src)
+		}
+	}
+	return img, 1 + int(float64(orp.Start+orp.End)*px/2)
+}
+
+// clearGraphics removes the last image from the screen. Kitty keeps images
+// after the cells under them are cleared.
+func clearGraphics(w io.Writer, mode string) {
+	if mode == "kitty" {
+		fmt.Fprint(w, "\x1b_Ga=d,d=A,q=2\x1b\\")
+	}
+}
+
+// kittyImage encodes img as PNG for the Kitty graphics protocol. The
+// previous word's image is deleted first, and replies are suppressed so
+// they don't arrive as key presses.
+func kittyImage(img image.Image) string {
+	var buf bytes.Buffer
+	png.Encode(&buf, img)
+	data := base64.StdEncoding.EncodeToString(buf.Bytes())
+
+	var sb strings.Builder
+	sb.WriteString("\x1b_Ga=d,d=A,q=2\x1b\\")
+	const chunk = 4096
+	for i := 0; i < len(data); i += chunk {
+		end := min(i+chunk, len(data))
+		more := 0
+		if end < len(data) {
+			more = 1
+		}
+		if i == 0 {
+			fmt.Fprintf(&sb, "\x1b_Ga=T,f=100,q=2,C=1,m=%d;%s\x1b\\", more, data[i:end])
+		} else {
+			fmt.Fprintf(&sb, "\x1b_Gm=%d;%s\x1b\\", more, data[i:end])
+		}
+	}
+	return sb.String()
+}
+
+// itermImage encodes img as an iTerm2 inline image
+func itermImage(img image.Image) string {
+	var buf bytes.Buffer
+	png.Encode(&buf, img)
+	b := img.Bounds()
+	return fmt.Sprintf("\x1b]1337;File=inline=1;size=%d;width=%dpx;height=%dpx;preserveAspectRatio=1:%s\a",
+		buf.Len(), b.Dx(), b.Dy(), base64.StdEncoding.EncodeToString(buf.Bytes()))
+}
+
+// sixelImage encodes img as sixels. Sixel has no alpha, so anti-aliased
+// edges are blended with the terminal background and quantized to a few
+// shades per color; fully transparent pixels are left unpainted.
+func sixelImage(img *image.RGBA, background color.RGBA) string {
+	const shades = 8
+	type paletteKey struct {
+		c     color.RGBA
+		shade int
+	}
+	palette := make(map[paletteKey]int)
+	var colors []color.RGBA
+	b := img.Bounds()
+	indexes := make([]int, b.Dx()*b.Dy())
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			p := img.RGBAAt(x, y)
+			if p.A == 0 {
+				indexes[y*b.Dx()+x] = -1
+				continue
+			}
+			// Un-premultiply to get the drawing color, then blend a shade of it
+			base := color.RGBA{uint8(int(p.R) * 255 / int(p.A)), uint8(int(p.G) * 255 / int(p.A)), uint8(int(p.B) * 255 / int(p.A)), 0xff}
+			shade := int(math.Round(float64(p.A) * shades / 255))
+			if shade == 0 {
+				indexes[y*b.Dx()+x] = -1
+				continue
+			}
+			key := paletteKey{base, shade}
+			idx, ok := palette[key]
+			if !ok {
+				if len(colors) >= 256 {
+					idx = len(colors) - 1
+				} else {
+					idx = len(colors)
+					palette[key] = idx
+					alpha := float64(shade) / shades
+					colors = append(colors, color.RGBA{
+						blend(base.R, background.R, alpha),
+						blend(base.G, background.G, alpha),
+						blend(base.B, background.B, alpha),
+						0xff,
+					})
+				}
+			}
+			indexes[y*b.Dx()+x] = idx
+		}
+	}
+
+	var sb strings.Builder
+	// P2=1: pixels without a color keep the background
+	fmt.Fprintf(&sb, "\x1bP0;1;0q\"1;1;%d;%d", b.Dx(), b.Dy())
+	for i, c := range colors {
+		fmt.Fprintf(&sb, "#%d;2;%d;%d;%d", i, int(c.R)*100/255, int(c.G)*100/255, int(c.B)*100/255)
+	}
+	for band := 0; band < b.Dy(); band += 6 {
+		for ci := range colors {
+			var row strings.Builder
+			used := false
+			run, prev := 0, byte(0)
+			flush := func() {
+				switch {
+				case run >= 4:
+					fmt.Fprintf(&row, "!%d%c", run, prev)
+				default:
+					for range run {
+						row.WriteByte(prev)
+					}
+				}
+			}
+			for x := 0; x < b.Dx(); x++ {
+				bits := 0
+				for dy := 0; dy < 6 && band+dy < b.Dy(); dy++ {
+					if indexes[(band+dy)*b.Dx()+x] == ci {
+						bits |= 1 << dy
+					}
+				}
+				if bits != 0 {
+					used = true
+				}
+				ch := byte(63 + bits)
+				if ch == prev {
+					run++
+					continue
+				}
+				flush()
+				run, prev = 1, ch
+			}
+			if !used {
+				continue
+			}
+			flush()
+			fmt.Fprintf(&sb, "#%d%s$", ci, row.String())
+		}
+		sb.WriteByte('-')
+	}
+	sb.WriteString("\x1b\\")
+	return sb.String()
+}
+
+func blend(fg, bg uint8, alpha float64) uint8 {
+	return uint8(math.Round(float64(fg)*alpha + float64(bg)*(1-alpha)))
+}
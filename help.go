@@ -0,0 +1,236 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Names shown for keys that aren't written as themselves
+var keyLabels = map[string]string{
+	"space": "Space", "enter": "Enter", "esc": "Esc", "tab": "Tab",
+	"backspace": "Backspace", "insert": "Ins", "delete": "Del",
+	"up": "↑", "down": "↓", "left": "←", "right": "→",
+	"home": "Home", "end": "End", "pgup": "PgUp", "pgdown": "PgDn",
+}
+
+// keyLabel writes a key name from keyDecoder the way help shows it, as in
+// "Ctrl+C" or "PgUp"
+func keyLabel(key string) string {
+	var prefix string
+	for _, mod := range []string{"ctrl+", "alt+", "shift+"} {
+		if rest, ok := strings.CutPrefix(key, mod); ok && rest != "" {
+			prefix += strings.ToUpper(mod[:1]) + mod[1:]
+			key = rest
+		}
+	}
+	if label, ok := keyLabels[key]; ok {
+		return prefix + label
+	}
+	if prefix != "" || (key[0] == 'f' && len(key) > 1) {
+		// Ctrl+C rather than Ctrl+c, F5 rather than f5
+		r, size := utf8.DecodeRuneInString(key)
+		key = string(unicode.ToUpper(r)) + key[size:]
+	}
+	return prefix + key
+}
+
+// keysFor returns the keys bound to the named action, shortest first
+func (km keymap) keysFor(name string) []string {
+	var keys []string
+	for key, bound := range km {
+		if bound == name {
+			keys = append(keys, key)
+		}
+	}
+	slices.SortFunc(keys, func(a, b string) int {
+		if len(a) != len(b) {
+			return len(a) - len(b)
+		}
+		return strings.Compare(a, b)
+	})
+	return keys
+}
+
+// label lists the keys bound to the named action for help, or "" if it is
+// unbound
+func (km keymap) label(name string) string {
+	keys := km.keysFor(name)
+	for i, key := range keys {
+		keys[i] = keyLabel(key)
+	}
+	return strings.Join(keys, " ")
+}
+
+// hint is the status line's reminder of the keys to pause and get help
+func (km keymap) hint() string {
+	var hints []string
+	for _, name := range []string{"pause", "help", "palette"} {
+		if keys := km.keysFor(name); len(keys) > 0 {
+			hints = append(hints, keyLabel(keys[0])+" "+name)
+		}
+	}
+	return strings.Join(hints, ", ")
+}
+
+// Overlays drawn over the word instead of the reading display
+const (
+	overlayNone = iota
+	overlayHelp
+	overlayPalette
+)
+
+// overlayState is the help screen or command palette being shown, shared
+// by the input goroutine and the render loop like searchState
+type overlayState struct {
+	mu       sync.Mutex
+	mode     int
+	query    string // Palette filter
+	selected int    // Palette entry picked with the arrow keys
+	scroll   int    // First help line shown
+}
+
+// overlayView is a copy of the overlay state for drawing a frame
+type overlayView struct {
+	Mode     int
+	Query    string
+	Selected int
+	Scroll   int
+}
+
+func (o *overlayState) view() overlayView {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return overlayView{o.mode, o.query, o.selected, o.scroll}
+}
+
+// edit changes the overlay with fn
+func (o *overlayState) edit(fn func(o *overlayState)) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	fn(o)
+}
+
+// helpLines lists every action with the keys bound to it, unbound actions
+// last, in two columns sized to fit width
+func helpLines(km keymap, width int) []string {
+	labelWidth := 0
+	for _, a := range actions {
+		labelWidth = max(labelWidth, utf8.RuneCountInString(km.label(a.Name)))
+	}
+	labelWidth = min(labelWidth, max(width/3, 2))
+
+	var bound, unbound []string
+	for _, a := range actions {
+		label := km.label(a.Name)
+		if label == "" {
+			unbound = append(unbound, fmt.Sprintf("  %-*s  %s \033[2m(%s)\033[0m", labelWidth, "", a.Description, a.Name))
+			continue
+		}
+		if utf8.RuneCountInString(label) > labelWidth {
+			label = string([]rune(label)[:labelWidth-1]) + "…"
+		}
+		bound = append(bound, fmt.Sprintf("  \033[1m%s\033[0m%s  %s", label, strings.Repeat(" ", labelWidth-utf8.RuneCountInString(label)), a.Description))
+	}
+	if len(unbound) > 0 {
+		bound = append(append(bound, "", "  \033[2mNot bound to a key:\033[0m"), unbound...)
+	}
+	return bound
+}
+
+// helpFrame is the help overlay for a screen of the given size, scrolled to
+// start at line scroll
+func helpFrame(km keymap, scroll, width, height int) []string {
+	lines := helpLines(km, width)
+	body := max(height-4, 1)
+	scroll = min(max(scroll, 0), max(len(lines)-body, 0))
+	frame := []string{"", "  \033[1mKeys\033[0m", ""}
+	frame = append(frame, lines[scroll:min(scroll+body, len(lines))]...)
+	for len(frame) < height-1 {
+		frame = append(frame, "")
+	}
+	footer := "Any other key closes help"
+	if len(lines) > body {
+		footer = "↑↓ scroll, any other key closes help"
+	}
+	return append(frame, "  \033[2m"+footer+"\033[0m")
+}
+
+// fuzzyScore says whether query's characters all appear in text in order,
+// scoring matches higher where they run together or start words
+func fuzzyScore(query, text string) (int, bool) {
+	query, text = strings.ToLower(query), strings.ToLower(text)
+	score, prev := 0, -2
+	q := []rune(query)
+	runes := []rune(text)
+	for i, r := range runes {
+		if len(q) == 0 {
+			break
+		}
+		if r != q[0] {
+			continue
+		}
+		score++
+		if i == prev+1 {
+			score += 5
+		}
+		if i == 0 || !unicode.IsLetter(runes[i-1]) {
+			score += 3
+		}
+		prev = i
+		q = q[1:]
+	}
+	return score, len(q) == 0
+}
+
+// paletteMatches returns the actions matching query, best first
+func paletteMatches(query string) []action {
+	type match struct {
+		action
+		score int
+	}
+	var matches []match
+	for _, a := range actions {
+		if score, ok := fuzzyScore(query, a.Name+" "+a.Description); ok {
+			matches = append(matches, match{a, score})
+		}
+	}
+	slices.SortStableFunc(matches, func(a, b match) int { return b.score - a.score })
+	found := make([]action, len(matches))
+	for i, m := range matches {
+		found[i] = m.action
+	}
+	return found
+}
+
+// paletteFrame is the command palette for a screen of the given size
+func paletteFrame(km keymap, v overlayView, width, height int) []string {
+	matches := paletteMatches(v.Query)
+	frame := []string{"", "  > " + v.Query + "█", ""}
+	body := max(height-4, 1)
+	first := max(v.Selected-body+1, 0)
+	for i := first; i < min(first+body, len(matches)); i++ {
+		a := matches[i]
+		line := fmt.Sprintf("%-*s  %s", min(20, width/4), a.Name, a.Description)
+		if label := km.label(a.Name); label != "" {
+			line += "  (" + label + ")"
+		}
+		if r := []rune(line); len(r) > width-4 {
+			line = string(r[:max(width-5, 0)]) + "…"
+		}
+		if i == v.Selected {
+			line = "\033[7m" + line + "\033[0m"
+		}
+		frame = append(frame, "  "+line)
+	}
+	if len(matches) == 0 {
+		frame = append(frame, "  \033[2mNo matching commands\033[0m")
+	}
+	for len(frame) < height-1 {
+		frame = append(frame, "")
+	}
+	return append(frame, "  \033[2m↑↓ choose, Enter runs, Esc closes\033[0m")
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// htmlText is the readable text of an HTML/XHTML document
+type htmlText struct {
+	Text     string
+	Title    string
+	Anchors  map[string]int // Element id -> byte offset into Text
+	Headings []section      // One section per h1-h6 element
+}
+
+var headingLevels = map[atom.Atom]int{
+	atom.H1: 0, atom.H2: 1, atom.H3: 2, atom.H4: 3, atom.H5: 4, atom.H6: 5,
+}
+
+// Elements whose contents are never shown to the reader
+var skippedElements = map[atom.Atom]bool{
+	atom.Head:     true,
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Noscript: true,
+	atom.Template: true,
+	atom.Svg:      true,
+	atom.Math:     true,
+}
+
+// Elements that start a new paragraph
+var blockElements = map[atom.Atom]bool{
+	atom.Address: true, atom.Article: true, atom.Aside: true, atom.Blockquote: true,
+	atom.Dd: true, atom.Div: true, atom.Dl: true, atom.Dt: true,
+	atom.Figcaption: true, atom.Figure: true, atom.Footer: true, atom.Header: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Hr: true, atom.Li: true, atom.Main: true, atom.Nav: true, atom.Ol: true,
+	atom.P: true, atom.Pre: true, atom.Section: true, atom.Table: true, atom.Caption: true,
+	atom.Tr: true, atom.Td: true, atom.Th: true, atom.Ul: true, atom.Body: true,
+}
+
+// extractHTMLText strips markup from an HTML or XHTML document, keeping
+// paragraph breaks as blank lines and recording where each id'd element starts.
+func extractHTMLText(r io.Reader) (htmlText, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return htmlText{}, err
+	}
+
+	t := &textBuilder{anchors: make(map[string]int)}
+	var title string
+	var headings []section
+	var walk func(n *html.Node, pre bool)
+	walk = func(n *html.Node, pre bool) {
+		switch n.Type {
+		case html.TextNode:
+			if pre {
+				t.writePre(n.Data)
+			} else {
+				t.writeInline(n.Data)
+			}
+			return
+		case html.ElementNode:
+			if n.DataAtom == atom.Title && title == "" {
+				title = strings.TrimSpace(nodeText(n))
+			}
+			if skippedElements[n.DataAtom] {
+				return
+			}
+			for _, attr := range n.Attr {
+				if attr.Key == "id" && attr.Val != "" {
+					t.anchor(attr.Val)
+				}
+			}
+			switch {
+			case n.DataAtom == atom.Br:
+				t.lineBreak()
+				return
+			case n.DataAtom == atom.Pre:
+				pre = true
+			}
+			if blockElements[n.DataAtom] {
+				t.paragraphBreak()
+			}
+			if level, ok := headingLevels[n.DataAtom]; ok {
+				if text := nodeText(n); text != "" {
+					headings = append(headings, section{Title: text, Offset: t.Len(), Level: level})
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c, pre)
+		}
+		if n.Type == html.ElementNode && blockElements[n.DataAtom] {
+			t.paragraphBreak()
+		}
+	}
+	walk(doc, false)
+
+	return htmlText{
+		Text:     t.String(),
+		Title:    title,
+		Anchors:  t.anchors,
+		Headings: headings,
+	}, nil
+}
+
+// nodeText returns the concatenated text of all descendants of n
+func nodeText(n *html.Node) string {
+	var sb strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return strings.Join(strings.Fields(sb.String()), " ")
+}
+
+// textBuilder accumulates extracted text, collapsing whitespace and
+// keeping paragraph breaks as a single blank line.
+type textBuilder struct {
+	sb      strings.Builder
+	space   bool // A space is pending before the next word
+	anchors map[string]int
+	pending []string // Anchors waiting for the next written text
+}
+
+func (t *textBuilder) String() string {
+	return strings.TrimRight(t.sb.String(), " \n")
+}
+
+func (t *textBuilder) Len() int {
+	return t.sb.Len()
+}
+
+// anchor records that id starts at the next piece of text written
+func (t *textBuilder) anchor(id string) {
+	t.pending = append(t.pending, id)
+}
+
+func (t *textBuilder) flushAnchors() {
+	for _, id := range t.pending {
+		if _, ok := t.anchors[id]; !ok {
+			t.anchors[id] = t.sb.Len()
+		}
+	}
+	t.pending = t.pending[:0]
+}
+
+func (t *textBuilder) writeInline(s string) {
+	if s == "" {
+		return
+	}
+	if isSpace(s[0]) {
+		t.space = true
+	}
+	fields := strings.Fields(s)
+	for i, f := range fields {
+		if i > 0 {
+			t.space = true
+		}
+		if t.space && t.sb.Len() > 0 && !t.atLineStart() {
+			t.sb.WriteByte(' ')
+		}
+		t.space = false
+		t.flushAnchors()
+		t.sb.WriteString(f)
+	}
+	if len(fields) > 0 && isSpace(s[len(s)-1]) {
+		t.space = true
+	}
+}
+
+func (t *textBuilder) writePre(s string) {
+	if s == "" {
+		return
+	}
+	t.flushAnchors()
+	t.sb.WriteString(s)
+	t.space = false
+}
+
+func (t *textBuilder) lineBreak() {
+	if t.sb.Len() > 0 && !t.atLineStart() {
+		t.sb.WriteByte('\n')
+	}
+	t.space = false
+}
+
+func (t *textBuilder) paragraphBreak() {
+	t.space = false
+	if t.sb.Len() == 0 {
+		return
+	}
+	s := t.sb.String()
+	switch {
+	case strings.HasSuffix(s, "\n\n"):
+	case strings.HasSuffix(s, "\n"):
+		t.sb.WriteByte('\n')
+	default:
+		t.sb.WriteString("\n\n")
+	}
+}
+
+func (t *textBuilder) atLineStart() bool {
+	s := t.sb.String()
+	return len(s) == 0 || s[len(s)-1] == '\n'
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r' || b == '\f'
+}
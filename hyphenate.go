@@ -0,0 +1,274 @@
+package main
+
+import (
+	"embed"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+//go:embed hyph/*.txt
+var hyphFiles embed.FS
+
+// hyphenLanguage is a set of embedded hyphenation patterns
+type hyphenLanguage struct {
+	Name              string // Value of -hyphenate, and the pattern file name
+	LeftMin, RightMin int    // Fewest letters kept before and after a break
+}
+
+// Languages with embedded patterns, selectable with -hyphenate
+var hyphenLanguages = []hyphenLanguage{
+	{"en-us", 2, 3},
+	{"de", 2, 2},
+	{"fr", 2, 3},
+	{"es", 2, 2},
+}
+
+// hyphenLanguageNames lists the languages for messages
+func hyphenLanguageNames() string {
+	names := make([]string, len(hyphenLanguages))
+	for i, lang := range hyphenLanguages {
+		names[i] = lang.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// findHyphenLanguage looks up the embedded patterns called name
+func findHyphenLanguage(name string) (hyphenLanguage, bool) {
+	for _, lang := range hyphenLanguages {
+		if lang.Name == name {
+			return lang, true
+		}
+	}
+	return hyphenLanguage{}, false
+}
+
+// hyphenator finds where words may be broken with Knuth–Liang patterns
+type hyphenator struct {
+	lang       hyphenLanguage
+	patterns   map[string][]uint8 // Letters of a pattern to its break values
+	maxPattern int                // Letters in the longest pattern
+	exceptions map[string][]int   // Words hyphenated by hand: break positions
+}
+
+// loadHyphenator reads the embedded patterns for lang. The files follow
+// TeX: \patterns lists patterns like "hy3ph", \hyphenation lists words
+// with their breaks marked, like "acad-e-my".
+func loadHyphenator(lang hyphenLanguage) (*hyphenator, error) {
+	data, err := hyphFiles.ReadFile("hyph/" + lang.Name + ".txt")
+	if err != nil {
+		return nil, err
+	}
+	h := &hyphenator{
+		lang:       lang,
+		patterns:   make(map[string][]uint8),
+		exceptions: make(map[string][]int),
+	}
+	section := ""
+	for line := range strings.Lines(string(data)) {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "" || strings.HasPrefix(line, "%"):
+			continue
+		case strings.HasPrefix(line, `\`):
+			section = line
+			continue
+		}
+		switch section {
+		case `\patterns`:
+			h.addPattern(line)
+		case `\hyphenation`:
+			var word strings.Builder
+			var breaks []int
+			n := 0
+			for _, r := range line {
+				if r == '-' {
+					breaks = append(breaks, n)
+					continue
+				}
+				word.WriteRune(r)
+				n++
+			}
+			h.exceptions[word.String()] = breaks
+		}
+	}
+	return h, nil
+}
+
+// addPattern splits a pattern into its letters and the value at each of
+// the positions around them
+func (h *hyphenator) addPattern(pattern string) {
+	var letters strings.Builder
+	values := []uint8{0}
+	for _, r := range pattern {
+		if r >= '0' && r <= '9' {
+			values[len(values)-1] = uint8(r - '0')
+			continue
+		}
+		letters.WriteRune(r)
+		values = append(values, 0)
+	}
+	h.patterns[letters.String()] = values
+	h.maxPattern = max(h.maxPattern, len(values)-1)
+}
+
+// breaks returns the rune positions in word where a hyphen may go, each a
+// break before that rune
+func (h *hyphenator) breaks(word string) []int {
+	lower := []rune(strings.ToLower(word))
+	n := len(lower)
+	if n < h.lang.LeftMin+h.lang.RightMin {
+		return nil
+	}
+	if breaks, ok := h.exceptions[string(lower)]; ok {
+		return breaks
+	}
+
+	// Match every pattern inside ".word." and keep the highest value at each
+	// position; odd values allow a break
+	dotted := append(append([]rune{'.'}, lower...), '.')
+	values := make([]uint8, len(dotted)+1)
+	for i := range dotted {
+		for j := i + 1; j <= min(i+h.maxPattern, len(dotted)); j++ {
+			pattern, ok := h.patterns[string(dotted[i:j])]
+			if !ok {
+				continue
+			}
+			for k, v := range pattern {
+				values[i+k] = max(values[i+k], v)
+			}
+		}
+	}
+
+	var breaks []int
+	for pos := h.lang.LeftMin; pos <= n-h.lang.RightMin; pos++ {
+		// values[k] is the position before dotted[k], which is lower[k-1]
+		if values[pos+1]%2 == 1 {
+			breaks = append(breaks, pos)
+		}
+	}
+	return breaks
+}
+
+// wordPiece is part of a word split by splitWord
+type wordPiece struct {
+	Text       string
+	Hyphenated bool // Text ends in a hyphen that isn't in the word
+}
+
+// splitWord breaks word into pieces of at most maxLen characters, each but
+// the last ending in a hyphen. It breaks at hyphenation points where it
+// can, then after slashes, dashes and other separators in URLs and
+// compounds, and cuts the word as a last resort.
+func (h *hyphenator) splitWord(word string, maxLen int) []wordPiece {
+	runes := []rune(word)
+	if maxLen < 2 || len(runes) <= maxLen {
+		return []wordPiece{{Text: word}}
+	}
+
+	// Break positions, true where a hyphen has to be added
+	points := make(map[int]bool)
+	for i := 1; i < len(runes); i++ {
+		if strings.ContainsRune("-/_.?&=#~", runes[i-1]) {
+			points[i] = false
+		}
+	}
+	if h != nil {
+		for start := 0; start < len(runes); {
+			if !unicode.IsLetter(runes[start]) {
+				start++
+				continue
+			}
+			end := start
+			for end < len(runes) && unicode.IsLetter(runes[end]) {
+				end++
+			}
+			for _, b := range h.breaks(string(runes[start:end])) {
+				points[start+b] = true
+			}
+			start = end
+		}
+	}
+
+	var pieces []wordPiece
+	for len(runes) > maxLen {
+		cut, hyphen := 0, true
+		for i := maxLen; i > 0; i-- {
+			if add, ok := points[i]; ok && (i < maxLen || !add) {
+				cut, hyphen = i, add
+				break
+			}
+		}
+		if cut == 0 {
+			cut = maxLen - 1
+		}
+		piece := wordPiece{Text: string(runes[:cut]), Hyphenated: hyphen}
+		if hyphen {
+			piece.Text += "-"
+		}
+		pieces = append(pieces, piece)
+
+		runes = runes[cut:]
+		shifted := make(map[int]bool, len(points))
+		for p, add := range points {
+			if p > cut {
+				shifted[p-cut] = add
+			}
+		}
+		points = shifted
+	}
+	return append(pieces, wordPiece{Text: string(runes)})
+}
+
+// splitLongWords replaces words longer than maxLen characters with their
+// pieces, keeping sections and pages pointing at the same words. Pieces
+// share the word's sentence and paragraph, and have their own offsets so
+// bookmarks resume at the right piece.
+func (d *document) splitLongWords(maxLen int, h *hyphenator) {
+	if maxLen <= 0 {
+		return
+	}
+	newIndex := make([]int, len(d.Tokens)+1)
+	tokens := make([]token, 0, len(d.Tokens))
+	for i, tok := range d.Tokens {
+		newIndex[i] = len(tokens)
+		if utf8.RuneCountInString(tok.Text) <= maxLen {
+			tokens = append(tokens, tok)
+			continue
+		}
+		pieces := h.splitWord(tok.Text, maxLen)
+		offset := tok.Offset
+		for k, piece := range pieces {
+			part := tok
+			part.Text = piece.Text
+			part.Offset = offset
+			part.Continued = k < len(pieces)-1
+			part.Hyphenated = piece.Hyphenated
+			tokens = append(tokens, part)
+			offset += len(piece.Text)
+			if piece.Hyphenated {
+				offset-- // The hyphen isn't in the source
+			}
+		}
+	}
+	newIndex[len(d.Tokens)] = len(tokens)
+
+	for i := range d.Sections {
+		d.Sections[i].Start = newIndex[d.Sections[i].Start]
+	}
+	for i := range d.Pages {
+		d.Pages[i] = newIndex[d.Pages[i]]
+	}
+	d.Tokens = tokens
+}
+
+// countWords counts the words in tokens, a split word counting once
+func countWords(tokens []token) int {
+	n := 0
+	for _, tok := range tokens {
+		if !tok.Continued {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// action is something a key can be bound to
+type action struct {
+	Name        string
+	Description string
+}
+
+// Actions that keys can be bound to, in the order they are listed
+var actions = []action{
+	{"pause", "Pause or resume"},
+	{"wpm+", "Read 25 WPM faster"},
+	{"wpm-", "Read 25 WPM slower"},
+	{"back", "Go back one word"},
+	{"forward", "Skip forward one word"},
+	{"start", "Go to the first word"},
+	{"end", "Go to the last word"},
+	{"jump-0", "Jump to 0%"},
+	{"jump-1", "Jump to 10%"},
+	{"jump-2", "Jump to 20%"},
+	{"jump-3", "Jump to 30%"},
+	{"jump-4", "Jump to 40%"},
+	{"jump-5", "Jump to 50%"},
+	{"jump-6", "Jump to 60%"},
+	{"jump-7", "Jump to 70%"},
+	{"jump-8", "Jump to 80%"},
+	{"jump-9", "Jump to 90%"},
+	{"back-sentence", "Go back to the start of the sentence"},
+	{"forward-sentence", "Go to the next sentence"},
+	{"back-paragraph", "Go back to the start of the paragraph"},
+	{"forward-paragraph", "Go to the next paragraph"},
+	{"prev-chapter", "Go back to the start of the chapter"},
+	{"next-chapter", "Go to the next chapter"},
+	{"replay-sentence", "Replay the last sentence at reduced speed"},
+	{"undo", "Go back to where you were before the last jump"},
+	{"goto", "Go to a word, percentage, page or chapter typed at a prompt"},
+	{"search", "Search the text, jumping to matches as you type"},
+	{"search-next", "Go to the next match of the last search"},
+	{"search-prev", "Go to the previous match of the last search"},
+	{"help", "Show the keys bound to every action"},
+	{"palette", "Find and run any action by name"},
+	{"cancel", "Clear the search match and any message"},
+	{"quit", "Save the position and quit"},
+}
+
+// keymap binds key names, as decoded by keyDecoder, to action names
+type keymap map[string]string
+
+// Key that always quits, even in prompts, so that a keymap can't leave the
+// reader without a way out
+const hardQuitKey = "ctrl+c"
+
+// Built-in keymaps, selectable with -keymap or "preset" in the keymap file
+var keymapPresets = map[string]keymap{
+	"default": {
+		"space": "pause",
+		"up":    "wpm+", "down": "wpm-",
+		"left": "back", "right": "forward",
+		"home": "start", "end": "end",
+		"(": "back-sentence", ")": "forward-sentence",
+		"{": "back-paragraph", "}": "forward-paragraph",
+		"[": "prev-chapter", "]": "next-chapter",
+		"pgup": "prev-chapter", "pgdown": "next-chapter",
+		"r": "replay-sentence", "u": "undo",
+		"/": "search", "n": "search-next", "N": "search-prev",
+		":": "goto",
+		"?": "help", "f1": "help", "ctrl+p": "palette",
+		"0": "jump-0", "1": "jump-1", "2": "jump-2", "3": "jump-3", "4": "jump-4",
+		"5": "jump-5", "6": "jump-6", "7": "jump-7", "8": "jump-8", "9": "jump-9",
+		"ctrl+c": "quit",
+	},
+	"vim": {
+		"h": "back", "l": "forward",
+		"k": "wpm+", "j": "wpm-",
+		"g": "start", "G": "end",
+		"q": "quit",
+	},
+	"emacs": {
+		"ctrl+b": "back", "ctrl+f": "forward",
+		"ctrl+p": "wpm+", "ctrl+n": "wpm-",
+		"alt+<": "start", "alt+>": "end",
+		"alt+a": "back-sentence", "alt+e": "forward-sentence",
+		"alt+{": "back-paragraph", "alt+}": "forward-paragraph",
+		"ctrl+_": "undo",
+		"ctrl+s": "search", "ctrl+r": "search-prev",
+		"alt+g":  "goto",
+		"alt+x":  "palette",
+		"ctrl+g": "cancel",
+	},
+}
+
+// Names of the presets, in the order they are listed
+var keymapPresetNames = []string{"default", "vim", "emacs"}
+
+// preset returns a copy of the named preset. Presets other than default
+// add their bindings to the default ones.
+func preset(name string) (keymap, bool) {
+	p, ok := keymapPresets[name]
+	if !ok {
+		return nil, false
+	}
+	km := maps.Clone(keymapPresets["default"])
+	maps.Copy(km, p)
+	return km, true
+}
+
+func getKeymapPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "speedread", "keymap")
+}
+
+// loadKeymap returns the keymap for the -keymap flag: a preset name or a
+// keymap file. Without one, the keymap file in the config directory is used
+// if there is one.
+func loadKeymap(spec string) (keymap, error) {
+	if km, ok := preset(spec); ok {
+		return km, nil
+	}
+	path := spec
+	if path == "" {
+		path = getKeymapPath()
+		if _, err := os.Stat(path); err != nil {
+			km, _ := preset("default")
+			return km, nil
+		}
+	}
+	return readKeymap(path)
+}
+
+// readKeymap reads a keymap file. Each line binds a key to an action, as in
+// "ctrl+s pause", or starts from a preset, as in "preset vim". Binding a
+// key to "none" unbinds it; # starts a comment. Ctrl+C always quits.
+func readKeymap(path string) (keymap, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	km, _ := preset("default")
+	scanner := bufio.NewScanner(f)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		fields := strings.Fields(scanner.Text())
+		for i, field := range fields {
+			// A comment starts at a field beginning with #, except for a
+			// lone # bound to an action, which is the # key
+			if strings.HasPrefix(field, "#") && !(i == 0 && field == "#" && len(fields) > 1 && isBindable(fields[1])) {
+				fields = fields[:i]
+				break
+			}
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s:%d: want a key and an action", path, lineNo)
+		}
+		key, name := fields[0], fields[1]
+		switch {
+		case key == hardQuitKey:
+			return nil, fmt.Errorf("%s:%d: %s always quits and can't be rebound", path, lineNo, key)
+		case key == "preset":
+			p, ok := preset(name)
+			if !ok {
+				return nil, fmt.Errorf("%s:%d: unknown preset %q (want one of: %s)", path, lineNo, name, strings.Join(keymapPresetNames, ", "))
+			}
+			km = p
+		case name == "none":
+			delete(km, key)
+		case findAction(name) < 0:
+			return nil, fmt.Errorf("%s:%d: unknown action %q", path, lineNo, name)
+		default:
+			km[key] = name
+		}
+	}
+	return km, scanner.Err()
+}
+
+// isBindable reports whether name can follow a key in a keymap file
+func isBindable(name string) bool {
+	return name == "none" || findAction(name) >= 0
+}
+
+// findAction returns the index of the named action, or -1
+func findAction(name string) int {
+	for i, a := range actions {
+		if a.Name == name {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// How long to wait for the rest of an escape sequence before taking Esc as
+// a key press of its own
+const escTimeout = 50 * time.Millisecond
+
+// keyDecoder turns terminal input into key names such as "a", "A", "space",
+// "enter", "esc", "up", "pgdown", "f5", "ctrl+b", "alt+x" or "ctrl+shift+left".
+// Input is read in the background, so sequences split across reads are put
+// back together.
+type keyDecoder struct {
+	input <-chan []byte
+	buf   []byte
+}
+
+func newKeyDecoder(r io.Reader) *keyDecoder {
+	input := make(chan []byte, 16)
+	go func() {
+		defer close(input)
+		for {
+			buf := make([]byte, 64)
+			n, err := r.Read(buf)
+			if n > 0 {
+				input <- buf[:n]
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return &keyDecoder{input: input}
+}
+
+// next blocks until a key is pressed and returns its name. It returns false
+// once the input is closed.
+func (d *keyDecoder) next() (string, bool) {
+	for {
+		if len(d.buf) == 0 {
+			data, ok := <-d.input
+			if !ok {
+				return "", false
+			}
+			d.buf = append(d.buf, data...)
+		}
+		name, size := parseKey(d.buf)
+		if size > 0 {
+			d.buf = d.buf[size:]
+			return name, true
+		}
+
+		// Incomplete: wait briefly for the rest, otherwise take the bytes
+		// as they are
+		select {
+		case data, ok := <-d.input:
+			if !ok {
+				return "", false
+			}
+			d.buf = append(d.buf, data...)
+		case <-time.After(escTimeout):
+			name, size := parseIncomplete(d.buf)
+			d.buf = d.buf[size:]
+			return name, true
+		}
+	}
+}
+
+// parseIncomplete names the key at the start of buf when the rest of its
+// sequence never came: a lone Esc, or a broken character
+func parseIncomplete(buf []byte) (string, int) {
+	if buf[0] == 0x1b {
+		return "esc", 1
+	}
+	return string(utf8.RuneError), 1
+}
+
+// Keys for the final byte of CSI and SS3 sequences
+var csiFinalKeys = map[byte]string{
+	'A': "up", 'B': "down", 'C': "right", 'D': "left",
+	'H': "home", 'F': "end", 'Z': "shift+tab",
+	'P': "f1", 'Q': "f2", 'R': "f3", 'S': "f4",
+}
+
+// Keys for the number of CSI ... ~ sequences
+var csiTildeKeys = map[int]string{
+	1: "home", 2: "insert", 3: "delete", 4: "end", 5: "pgup", 6: "pgdown",
+	7: "home", 8: "end", 11: "f1", 12: "f2", 13: "f3", 14: "f4",
+	15: "f5", 17: "f6", 18: "f7", 19: "f8", 20: "f9", 21: "f10",
+	23: "f11", 24: "f12",
+}
+
+// parseKey decodes the key at the start of buf and returns its name and
+// length, or a length of 0 if buf ends partway through the key
+func parseKey(buf []byte) (string, int) {
+	b := buf[0]
+	switch {
+	case b == 0x1b:
+		if len(buf) == 1 {
+			return "", 0
+		}
+		switch buf[1] {
+		case '[':
+			return parseCSI(buf)
+		case 'O':
+			if len(buf) < 3 {
+				return "", 0
+			}
+			if name, ok := csiFinalKeys[buf[2]]; ok {
+				return name, 3
+			}
+			return "alt+O", 2
+		case 0x1b:
+			return "esc", 1
+		}
+		name, size := parseKey(buf[1:])
+		if size == 0 {
+			return "", 0
+		}
+		return "alt+" + name, size + 1
+	case b == '\r' || b == '\n':
+		return "enter", 1
+	case b == '\t':
+		return "tab", 1
+	case b == 0x7f || b == 0x08:
+		return "backspace", 1
+	case b == 0:
+		return "ctrl+space", 1
+	case b < 0x1b:
+		return "ctrl+" + string(rune('a'+b-1)), 1
+	case b < ' ':
+		return "ctrl+" + string(rune(b+'@')), 1
+	case b == ' ':
+		return "space", 1
+	}
+
+	if !utf8.FullRune(buf) {
+		return "", 0
+	}
+	r, size := utf8.DecodeRune(buf)
+	return string(r), size
+}
+
+// parseCSI decodes ESC [ params final. The second parameter, when there is
+// one, holds the modifiers: 1 + shift(1) + alt(2) + ctrl(4).
+func parseCSI(buf []byte) (string, int) {
+	end := 2
+	for end < len(buf) && buf[end] >= 0x20 && buf[end] < 0x40 {
+		end++
+	}
+	if end == len(buf) {
+		return "", 0
+	}
+	final := buf[end]
+	params := strings.Split(string(buf[2:end]), ";")
+	size := end + 1
+
+	var name string
+	if final == '~' {
+		n, _ := strconv.Atoi(params[0])
+		name = csiTildeKeys[n]
+	} else {
+		name = csiFinalKeys[final]
+	}
+	if name == "" {
+		return "unknown", size
+	}
+
+	if len(params) > 1 {
+		mod, _ := strconv.Atoi(params[1])
+		mod--
+		prefix := ""
+		if mod&4 != 0 {
+			prefix += "ctrl+"
+		}
+		if mod&2 != 0 {
+			prefix += "alt+"
+		}
+		if mod&1 != 0 {
+			prefix += "shift+"
+		}
+		name = prefix + name
+	}
+	return name, size
+}
+
+// editText applies a key typed at a prompt to text: printable keys are
+// added, backspace deletes the last character and ctrl+u clears it all. It
+// returns false for keys that don't edit text.
+func editText(text, key string) (string, bool) {
+	switch key {
+	case "backspace":
+		_, size := utf8.DecodeLastRuneInString(text)
+		return text[:len(text)-size], true
+	case "ctrl+u":
+		return "", true
+	case "space":
+		return text + " ", true
+	}
+	r, size := utf8.DecodeRuneInString(key)
+	if size != len(key) || !unicode.IsPrint(r) {
+		return text, false
+	}
+	return text + key, true
+}
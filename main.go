@@ -1,14 +1,21 @@
 package main
 
 import (
-	"bufio"
+	"archive/zip"
+	"bytes"
+	"cmp"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
+	"math"
+	"mime"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 	"sync/atomic"
 	"time"
@@ -17,351 +24,69 @@ import (
 	"golang.org/x/term"
 )
 
-// ASCII art font - each letter is 5 rows tall
-var font = map[rune][]string{
-	'A': {
-		"  █████  ",
-		" ██   ██ ",
-		" ███████ ",
-		" ██   ██ ",
-		" ██   ██ ",
-	},
-	'B': {
-		" ██████  ",
-		" ██   ██ ",
-		" ██████  ",
-		" ██   ██ ",
-		" ██████  ",
-	},
-	'C': {
-		"  █████  ",
-		" ██      ",
-		" ██      ",
-		" ██      ",
-		"  █████  ",
-	},
-	'D': {
-		" ██████  ",
-		" ██   ██ ",
-		" ██   ██ ",
-		" ██   ██ ",
-		" ██████  ",
-	},
-	'E': {
-		" ███████ ",
-		" ██      ",
-		" █████   ",
-		" ██      ",
-		" ███████ ",
-	},
-	'F': {
-		" ███████ ",
-		" ██      ",
-		" █████   ",
-		" ██      ",
-		" ██      ",
-	},
-	'G': {
-		"  █████  ",
-		" ██      ",
-		" ██  ███ ",
-		" ██   ██ ",
-		"  █████  ",
-	},
-	'H': {
-		" ██   ██ ",
-		" ██   ██ ",
-		" ███████ ",
-		" ██   ██ ",
-		" ██   ██ ",
-	},
-	'I': {
-		" ███████ ",
-		"   ██    ",
-		"   ██    ",
-		"   ██    ",
-		" ███████ ",
-	},
-	'J': {
-		" ███████ ",
-		"     ██  ",
-		"     ██  ",
-		" ██  ██  ",
-		"  ████   ",
-	},
-	'K': {
-		" ██   ██ ",
-		" ██  ██  ",
-		" █████   ",
-		" ██  ██  ",
-		" ██   ██ ",
-	},
-	'L': {
-		" ██      ",
-		" ██      ",
-		" ██      ",
-		" ██      ",
-		" ███████ ",
-	},
-	'M': {
-		" ██   ██ ",
-		" ███ ███ ",
-		" ██ █ ██ ",
-		" ██   ██ ",
-		" ██   ██ ",
-	},
-	'N': {
-		" ██   ██ ",
-		" ███  ██ ",
-		" ██ █ ██ ",
-		" ██  ███ ",
-		" ██   ██ ",
-	},
-	'O': {
-		"  █████  ",
-		" ██   ██ ",
-		" ██   ██ ",
-		" ██   ██ ",
-		"  █████  ",
-	},
-	'P': {
-		" ██████  ",
-		" ██   ██ ",
-		" ██████  ",
-		" ██      ",
-		" ██      ",
-	},
-	'Q': {
-		"  █████  ",
-		" ██   ██ ",
-		" ██   ██ ",
-		" ██  ██  ",
-		"  ████ █ ",
-	},
-	'R': {
-		" ██████  ",
-		" ██   ██ ",
-		" ██████  ",
-		" ██  ██  ",
-		" ██   ██ ",
-	},
-	'S': {
-		"  █████  ",
-		" ██      ",
-		"  █████  ",
-		"      ██ ",
-		"  █████  ",
-	},
-	'T': {
-		" ███████ ",
-		"   ██    ",
-		"   ██    ",
-		"   ██    ",
-		"   ██    ",
-	},
-	'U': {
-		" ██   ██ ",
-		" ██   ██ ",
-		" ██   ██ ",
-		" ██   ██ ",
-		"  █████  ",
-	},
-	'V': {
-		" ██   ██ ",
-		" ██   ██ ",
-		" ██   ██ ",
-		"  ██ ██  ",
-		"   ███   ",
-	},
-	'W': {
-		" ██   ██ ",
-		" ██   ██ ",
-		" ██ █ ██ ",
-		" ███ ███ ",
-		" ██   ██ ",
-	},
-	'X': {
-		" ██   ██ ",
-		"  ██ ██  ",
-		"   ███   ",
-		"  ██ ██  ",
-		" ██   ██ ",
-	},
-	'Y': {
-		" ██   ██ ",
-		"  ██ ██  ",
-		"   ███   ",
-		"   ██    ",
-		"   ██    ",
-	},
-	'Z': {
-		" ███████ ",
-		"     ██  ",
-		"   ██    ",
-		"  ██     ",
-		" ███████ ",
-	},
-	'0': {
-		"  █████  ",
-		" ██  ███ ",
-		" ██ █ ██ ",
-		" ███  ██ ",
-		"  █████  ",
-	},
-	'1': {
-		"   ██    ",
-		"  ███    ",
-		"   ██    ",
-		"   ██    ",
-		" ███████ ",
-	},
-	'2': {
-		"  █████  ",
-		" ██   ██ ",
-		"    ██   ",
-		"  ██     ",
-		" ███████ ",
-	},
-	'3': {
-		"  █████  ",
-		"      ██ ",
-		"   ████  ",
-		"      ██ ",
-		"  █████  ",
-	},
-	'4': {
-		" ██   ██ ",
-		" ██   ██ ",
-		" ███████ ",
-		"      ██ ",
-		"      ██ ",
-	},
-	'5': {
-		" ███████ ",
-		" ██      ",
-		" ██████  ",
-		"      ██ ",
-		" ██████  ",
-	},
-	'6': {
-		"  █████  ",
-		" ██      ",
-		" ██████  ",
-		" ██   ██ ",
-		"  █████  ",
-	},
-	'7': {
-		" ███████ ",
-		"     ██  ",
-		"    ██   ",
-		"   ██    ",
-		"   ██    ",
-	},
-	'8': {
-		"  █████  ",
-		" ██   ██ ",
-		"  █████  ",
-		" ██   ██ ",
-		"  █████  ",
-	},
-	'9': {
-		"  █████  ",
-		" ██   ██ ",
-		"  ██████ ",
-		"      ██ ",
-		"  █████  ",
-	},
-	'.': {
-		"         ",
-		"         ",
-		"         ",
-		"         ",
-		"   ██    ",
-	},
-	',': {
-		"         ",
-		"         ",
-		"         ",
-		"   ██    ",
-		"  ██     ",
-	},
-	'!': {
-		"   ██    ",
-		"   ██    ",
-		"   ██    ",
-		"         ",
-		"   ██    ",
-	},
-	'?': {
-		"  █████  ",
-		" ██   ██ ",
-		"    ██   ",
-		"         ",
-		"    ██   ",
-	},
-	'\'': {
-		"   ██    ",
-		"  ██     ",
-		"         ",
-		"         ",
-		"         ",
-	},
-	'"': {
-		" ██  ██  ",
-		" ██  ██  ",
-		"         ",
-		"         ",
-		"         ",
-	},
-	'-': {
-		"         ",
-		"         ",
-		" ███████ ",
-		"         ",
-		"         ",
-	},
-	' ': {
-		"         ",
-		"         ",
-		"         ",
-		"         ",
-		"         ",
-	},
-}
-
-const fontHeight = 5
-const charWidth = 9            // All characters are exactly 9 columns wide
 const targetHeightPercent = 0.5 // Use 50% of terminal height for text
 
-func renderWord(word string, termWidth, termHeight int, focal bool, focalColorCode string, maxWordLen int) []string {
-	word = strings.ToUpper(word)
-	wordRunes := []rune(word)
-	wordLen := len(wordRunes)
-
-	// Calculate ORP index for focal point highlighting
-	orpIndex := calculateORP(wordLen)
+// renderOptions control how words are drawn
+type renderOptions struct {
+	Face           fontFace
+	Mode           string // One of renderModes
+	Focal          bool
+	FocalColorCode string
+	Graphics       *graphicsTerm // Terminal image support, for image modes
+}
 
-	// Calculate total width of the word (all chars are same width)
-	totalWidth := wordLen * charWidth
+// layout is the sizing shared by every word at one terminal size. It is
+// recomputed when the terminal is resized.
+type layout struct {
+	Width, Height int
+	MaxWordLen    int
+	BaseScale     float64 // Scale at which every word has the same height
+}
 
-	// Calculate scale factor to fit terminal
+func newLayout(termWidth, termHeight, maxWordLen int, face fontFace) layout {
 	maxTextWidth := termWidth - 4 // Leave some margin
 	maxTextHeight := termHeight - 4
 
 	// Calculate base scale for uniform height across all words
 	// Use the smaller of: height-based scale OR width-based scale for longest word
-	heightScale := float64(maxTextHeight) * targetHeightPercent / float64(fontHeight)
-	referenceWidth := maxWordLen * charWidth
+	heightScale := float64(maxTextHeight) * targetHeightPercent / float64(face.Height())
+	referenceWidth := max(maxWordLen*face.CellWidth(), 1)
 	widthScale := float64(maxTextWidth) / float64(referenceWidth)
 
-	baseScale := heightScale
-	if widthScale < heightScale {
-		baseScale = widthScale
+	return layout{
+		Width:      termWidth,
+		Height:     termHeight,
+		MaxWordLen: maxWordLen,
+		BaseScale:  min(heightScale, widthScale),
+	}
+}
+
+func renderWord(word string, lay layout, opts renderOptions) []string {
+	if slices.Contains(graphicsProtocols, opts.Mode) {
+		if lines := renderImageWord(word, lay, opts); lines != nil {
+			return lines
+		}
+		opts.Mode = "blocks"
+	}
+
+	termWidth, termHeight := lay.Width, lay.Height
+	wordRunes := displayRunes(word)
+	wordLen := len(wordRunes)
+
+	// Calculate ORP index for focal point highlighting
+	orpIndex := calculateORP(wordLen)
+
+	// Glyphs can differ in width, so measure the rendered word
+	lines, spans := opts.Face.Render(wordRunes)
+	totalWidth := 0
+	if len(lines) > 0 {
+		totalWidth = len([]rune(lines[0]))
 	}
 
 	// Use baseScale for all words, but scale down further if word is too wide
-	scale := baseScale
-	scaledWidth := float64(totalWidth) * baseScale
+	maxTextWidth := lay.Width - 4
+	scale := lay.BaseScale
+	scaledWidth := float64(totalWidth) * scale
 	if scaledWidth > float64(maxTextWidth) {
 		// Word too wide even at baseScale - scale down to fit
 		scale = float64(maxTextWidth) / float64(totalWidth)
@@ -371,57 +96,50 @@ func renderWord(word string, termWidth, termHeight int, focal bool, focalColorCo
 	if scale > 3.0 {
 		scale = 3.0
 	}
-	// Cap minimum scale at 1.0 (no scaling below base font)
-	if scale < 1.0 {
-		scale = 1.0
+	// Cap minimum scale at 1.0 (no scaling below base font). Sub-cell modes
+	// draw two pixels per cell, so they can go down to half size.
+	minScale := 1.0
+	if opts.Mode != "blocks" {
+		minScale = 0.5
 	}
-
-	// Calculate integer scale factor
-	scaleFactor := int(scale + 0.5)
-	if scaleFactor < 1 {
-		scaleFactor = 1
+	if scale < minScale {
+		scale = minScale
 	}
 
-	// Build the output lines
-	lines := make([]string, fontHeight)
-	for row := 0; row < fontHeight; row++ {
-		var line strings.Builder
-		for _, ch := range word {
-			glyph, ok := font[ch]
-			if !ok {
-				glyph = font[' ']
-			}
-			if row < len(glyph) {
-				// Ensure each glyph row is exactly charWidth characters
-				glyphRow := glyph[row]
-				runeCount := len([]rune(glyphRow))
-				if runeCount < charWidth {
-					glyphRow = glyphRow + strings.Repeat(" ", charWidth-runeCount)
-				} else if runeCount > charWidth {
-					glyphRow = string([]rune(glyphRow)[:charWidth])
-				}
-				line.WriteString(glyphRow)
-			} else {
-				line.WriteString(strings.Repeat(" ", charWidth))
-			}
+	if opts.Mode == "blocks" {
+		// Calculate integer scale factor
+		scaleFactor := int(scale + 0.5)
+		if scaleFactor < 1 {
+			scaleFactor = 1
 		}
-		lines[row] = line.String()
-	}
+		scale = float64(scaleFactor)
 
-	// Scale up if scale > 1 (use rounding for better accuracy)
-	if scale > 1.0 {
-		lines = scaleUp(lines, scaleFactor)
+		// Scale up if scale > 1 (use rounding for better accuracy)
+		if scaleFactor > 1 {
+			lines = scaleUp(lines, scaleFactor)
+		}
+	} else {
+		lines = rasterize(lines, scale, opts.Mode)
 	}
 
-	// Calculate scaled character width
-	scaledCharWidth := charWidth * scaleFactor
+	// Columns of the ORP character after scaling
+	var orpSpan glyphSpan
+	if orpIndex < len(spans) {
+		orpSpan = glyphSpan{
+			int(float64(spans[orpIndex].Start) * scale),
+			int(math.Ceil(float64(spans[orpIndex].End) * scale)),
+		}
+	}
 
 	// Calculate horizontal positioning
-	lineWidth := len([]rune(lines[0]))
+	lineWidth := 0
+	if len(lines) > 0 {
+		lineWidth = len([]rune(lines[0]))
+	}
 	var padding int
-	if focal {
+	if opts.Focal {
 		// ORP-based centering: position ORP character center at screen center
-		orpCenterCol := orpIndex*scaledCharWidth + (scaledCharWidth / 2)
+		orpCenterCol := (orpSpan.Start + orpSpan.End) / 2
 		padding = (termWidth / 2) - orpCenterCol
 	} else {
 		// Traditional centering
@@ -445,11 +163,11 @@ func renderWord(word string, termWidth, termHeight int, focal bool, focalColorCo
 			lines[i] = string(lineRunes[:termWidth])
 		}
 
-		if focal && wordLen > 0 {
+		if opts.Focal && wordLen > 0 {
 			// Calculate ORP column range (after padding)
-			orpStartCol := padding + orpIndex*scaledCharWidth
-			orpEndCol := orpStartCol + scaledCharWidth
-			lines[i] = colorizeORPColumn(lines[i], orpStartCol, orpEndCol, focalColorCode)
+			orpStartCol := padding + orpSpan.Start
+			orpEndCol := padding + orpSpan.End
+			lines[i] = colorizeORPColumn(lines[i], orpStartCol, orpEndCol, opts.FocalColorCode)
 		}
 	}
 
@@ -549,83 +267,201 @@ func colorizeORPColumn(line string, startCol, endCol int, colorCode string) stri
 	return result.String()
 }
 
-func clearScreen() {
-	fmt.Print("\033[2J\033[H")
-}
-
 func isURL(input string) bool {
 	return strings.HasPrefix(input, "http://") || strings.HasPrefix(input, "https://")
 }
 
-func fetchURL(url string) (string, error) {
+// fetchURL downloads a URL, returning the body and its media type
+func fetchURL(url string) ([]byte, string, error) {
 	resp, err := http.Get(url)
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch URL: %w", err)
+		return nil, "", fmt.Errorf("failed to fetch URL: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("HTTP error: %s", resp.Status)
+		return nil, "", fmt.Errorf("HTTP error: %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch URL: %w", err)
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	return body, mediaType, nil
+}
+
+// readHTML extracts the article text of an HTML page using readability, or
+// just strips the tags when opts.RawHTML is set. base is used to resolve
+// relative links.
+func readHTML(content []byte, base *url.URL, opts readOptions) (source, error) {
+	if !opts.RawHTML {
+		article, err := readability.FromReader(bytes.NewReader(content), base)
+		if err != nil {
+			return source{}, fmt.Errorf("failed to extract content: %w", err)
+		}
+		content = []byte(article.Content)
 	}
 
-	article, err := readability.FromReader(resp.Body, nil)
+	doc, err := extractHTMLText(bytes.NewReader(content))
 	if err != nil {
-		return "", fmt.Errorf("failed to extract content: %w", err)
+		return source{}, fmt.Errorf("failed to parse HTML: %w", err)
 	}
+	return source{Text: doc.Text, Sections: doc.Headings}, nil
+}
 
-	return article.TextContent, nil
+// source is the text of an input along with any structure recovered from its format
+type source struct {
+	Text     string
+	Sections []section
+	Pages    []int // Byte offset into Text where each page starts (paginated formats only)
 }
 
-func readInput(input string) (string, error) {
-	// Check if input is a URL
-	if isURL(input) {
-		return fetchURL(input)
+// section marks the start of a chapter or heading in source.Text
+type section struct {
+	Title  string
+	Offset int // Byte offset into Text
+	Level  int // Nesting depth, 0 for top-level chapters
+}
+
+func sortSections(sections []section) {
+	sort.SliceStable(sections, func(i, j int) bool {
+		return sections[i].Offset < sections[j].Offset
+	})
+}
+
+// readOptions controls how document formats are converted to text
+type readOptions struct {
+	Format       string // Input format, or "auto" to detect it
+	InlineExtras bool   // Read footnotes and tables inline (DOCX/ODT)
+	SkipCode     bool   // Leave out code blocks (Markdown)
+	RawHTML      bool   // Strip tags instead of extracting the article (HTML)
+}
+
+// Input formats accepted by -format
+var inputFormats = []string{"auto", "text", "markdown", "html", "epub", "pdf", "docx", "odt"}
+
+// detectFormat guesses the format of an input from its content, filename
+// and (for URLs) the media type the server reported
+func detectFormat(name, mediaType string, content []byte) string {
+	if isZip(content) {
+		if zr, err := zip.NewReader(bytes.NewReader(content), int64(len(content))); err == nil {
+			switch {
+			case isEPUB(zr):
+				return "epub"
+			case isDOCX(zr):
+				return "docx"
+			case isODT(zr):
+				return "odt"
+			}
+		}
+	}
+	if isPDF(content) {
+		return "pdf"
+	}
+	if isMarkdownFile(name) {
+		return "markdown"
+	}
+	if isHTMLFile(name) || mediaType == "text/html" || mediaType == "application/xhtml+xml" || looksLikeHTML(content) {
+		return "html"
+	}
+	return "text"
+}
+
+// isHTMLFile reports whether a filename has an HTML extension
+func isHTMLFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".html", ".htm", ".xhtml":
+		return true
+	}
+	return false
+}
+
+// looksLikeHTML reports whether content starts like an HTML document
+func looksLikeHTML(content []byte) bool {
+	head := content
+	if len(head) > 512 {
+		head = head[:512]
 	}
+	head = bytes.ToLower(bytes.TrimSpace(bytes.TrimPrefix(head, []byte("\xef\xbb\xbf"))))
+	return bytes.HasPrefix(head, []byte("<!doctype html")) || bytes.HasPrefix(head, []byte("<html"))
+}
 
-	var reader io.Reader
+func readInput(input string, opts readOptions) (source, error) {
+	var content []byte
+	var mediaType string
+	var base *url.URL
+	var err error
 
-	if input != "" {
-		file, err := os.Open(input)
+	if isURL(input) {
+		content, mediaType, err = fetchURL(input)
 		if err != nil {
-			return "", fmt.Errorf("failed to open file: %w", err)
+			return source{}, err
 		}
-		defer file.Close()
-		reader = file
+		base, _ = url.Parse(input)
 	} else {
-		// Check if stdin has data
-		stat, _ := os.Stdin.Stat()
-		if (stat.Mode() & os.ModeCharDevice) != 0 {
-			return "", fmt.Errorf("no input: provide a filename, URL, or pipe text to stdin")
+		var reader io.Reader
+		if input != "" {
+			file, err := os.Open(input)
+			if err != nil {
+				return source{}, fmt.Errorf("failed to open file: %w", err)
+			}
+			defer file.Close()
+			reader = file
+
+			// Relative links in local HTML resolve against the file's location
+			if absPath, err := filepath.Abs(input); err == nil {
+				base = &url.URL{Scheme: "file", Path: filepath.ToSlash(absPath)}
+			}
+		} else {
+			// Check if stdin has data
+			stat, _ := os.Stdin.Stat()
+			if (stat.Mode() & os.ModeCharDevice) != 0 {
+				return source{}, fmt.Errorf("no input: provide a filename, URL, or pipe text to stdin")
+			}
+			reader = os.Stdin
 		}
-		reader = os.Stdin
-	}
 
-	content, err := io.ReadAll(reader)
-	if err != nil {
-		return "", fmt.Errorf("failed to read input: %w", err)
+		content, err = io.ReadAll(reader)
+		if err != nil {
+			return source{}, fmt.Errorf("failed to read input: %w", err)
+		}
 	}
 
-	return string(content), nil
-}
-
-func tokenizeWords(text string) []string {
-	scanner := bufio.NewScanner(strings.NewReader(text))
-	scanner.Split(bufio.ScanWords)
+	format := opts.Format
+	if format == "" || format == "auto" {
+		format = detectFormat(input, mediaType, content)
+	}
 
-	var words []string
-	for scanner.Scan() {
-		word := scanner.Text()
-		if word != "" {
-			words = append(words, word)
+	switch format {
+	case "epub", "docx", "odt":
+		zr, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+		if err != nil {
+			return source{}, fmt.Errorf("invalid %s: %w", strings.ToUpper(format), err)
 		}
+		switch format {
+		case "epub":
+			return readEPUB(zr)
+		case "docx":
+			return readDOCX(zr, opts)
+		default:
+			return readODT(zr, opts)
+		}
+	case "pdf":
+		return readPDF(content)
+	case "markdown":
+		return readMarkdown(string(content), opts), nil
+	case "html":
+		return readHTML(content, base, opts)
 	}
-	return words
+
+	return source{Text: string(content)}, nil
 }
 
-func findMaxWordLen(words []string) int {
+func findMaxWordLen(tokens []token) int {
 	maxLen := 0
-	for _, word := range words {
-		wordLen := len([]rune(word))
+	for _, tok := range tokens {
+		wordLen := len(displayRunes(tok.Text))
 		if wordLen > maxLen {
 			maxLen = wordLen
 		}
@@ -639,37 +475,13 @@ func findMaxWordLen(words []string) int {
 
 func getTerminalSize() (width, height int) {
 	width, height, err := term.GetSize(int(os.Stdout.Fd()))
-	if err != nil {
-		// Default fallback
+	if err != nil || width <= 0 || height <= 0 {
+		// Default fallback, also for terminals that don't report a size
 		return 80, 24
 	}
 	return width, height
 }
 
-func endsWithPunctuation(word string) bool {
-	if len(word) == 0 {
-		return false
-	}
-	lastChar := rune(word[len(word)-1])
-	switch lastChar {
-	case '.', ',', '!', '?', ';', ':', '"', '\'':
-		return true
-	}
-	return false
-}
-
-func endsWithSentence(word string) bool {
-	if len(word) == 0 {
-		return false
-	}
-	lastChar := rune(word[len(word)-1])
-	switch lastChar {
-	case '.', '!', '?':
-		return true
-	}
-	return false
-}
-
 // Bookmark functions for saving/resuming reading position
 func getBookmarkPath() string {
 	home, err := os.UserHomeDir()
@@ -679,8 +491,26 @@ func getBookmarkPath() string {
 	return filepath.Join(home, ".config", "speedread", "bookmarks.json")
 }
 
-func loadBookmarks() map[string]int {
-	bookmarks := make(map[string]int)
+// bookmark is a saved reading position. Offset is the word's byte offset
+// in the source text, which stays accurate even if tokenization changes.
+type bookmark struct {
+	Word   int `json:"word"`
+	Offset int `json:"offset"`
+}
+
+// UnmarshalJSON also accepts the plain word index saved by older versions
+func (b *bookmark) UnmarshalJSON(data []byte) error {
+	var word int
+	if err := json.Unmarshal(data, &word); err == nil {
+		*b = bookmark{Word: word, Offset: -1}
+		return nil
+	}
+	type plain bookmark
+	return json.Unmarshal(data, (*plain)(b))
+}
+
+func loadBookmarks() map[string]bookmark {
+	bookmarks := make(map[string]bookmark)
 	path := getBookmarkPath()
 	if path == "" {
 		return bookmarks
@@ -695,7 +525,7 @@ func loadBookmarks() map[string]int {
 	return bookmarks
 }
 
-func saveBookmark(filename string, position int) {
+func saveBookmark(filename string, doc *document, position int) {
 	path := getBookmarkPath()
 	if path == "" || filename == "" {
 		return
@@ -713,10 +543,10 @@ func saveBookmark(filename string, position int) {
 		absPath = filename
 	}
 
-	if position <= 0 {
+	if position <= 0 || position >= len(doc.Tokens) {
 		delete(bookmarks, absPath) // Remove bookmark if at start
 	} else {
-		bookmarks[absPath] = position
+		bookmarks[absPath] = bookmark{Word: position, Offset: doc.Tokens[position].Offset}
 	}
 
 	data, err := json.Marshal(bookmarks)
@@ -726,7 +556,8 @@ func saveBookmark(filename string, position int) {
 	os.WriteFile(path, data, 0644)
 }
 
-func getBookmark(filename string) int {
+// getBookmark returns the saved word index for filename, or 0 if there is none
+func getBookmark(filename string, doc *document) int {
 	if filename == "" {
 		return 0
 	}
@@ -736,8 +567,14 @@ func getBookmark(filename string) int {
 		absPath = filename
 	}
 
-	bookmarks := loadBookmarks()
-	return bookmarks[absPath]
+	b, ok := loadBookmarks()[absPath]
+	if !ok {
+		return 0
+	}
+	if b.Offset >= 0 {
+		return doc.indexAtOffset(b.Offset)
+	}
+	return b.Word
 }
 
 func formatTimeRemaining(remainingWords, wpm int) string {
@@ -782,14 +619,87 @@ func renderProgressBar(width, current, total int) string {
 
 func main() {
 	wpm := flag.Int("wpm", 200, "Words per minute (10-1000)")
-	punctPause := flag.Int("punct-pause", 0, "Extra pause after punctuation in milliseconds")
-	flag.IntVar(punctPause, "p", 0, "Extra pause after punctuation in milliseconds (shorthand)")
+	// Pauses are in milliseconds (300) or multiples of the word time (1.5x)
+	pauses := pauseTable{Sentence: pauseLength{Fixed: 150 * time.Millisecond}}
+	flag.Var(&pauses.Clause, "pause-clause", "Extra pause after commas and other punctuation, in ms or word times (e.g. 200, 0.5x)")
+	flag.Var(&pauses.Clause, "punct-pause", "Extra pause after punctuation (same as -pause-clause)")
+	flag.Var(&pauses.Clause, "p", "Extra pause after punctuation (shorthand)")
+	flag.Var(&pauses.Sentence, "pause-sentence", "Extra pause at the end of a sentence, in ms or word times")
+	flag.Var(&pauses.Paragraph, "pause-paragraph", "Extra pause at the end of a paragraph, in ms or word times")
+	flag.Var(&pauses.Section, "pause-section", "Extra pause at the end of a chapter or section, in ms or word times")
+	flag.Var(&pauses.Dialogue, "pause-dialogue", "Extra pause after closing quotes, in ms or word times")
 	focal := flag.Bool("focal", true, "Enable focal point highlighting (Spritz-style)")
 	focalColor := flag.String("focal-color", "red", "Focal point color (black, red, green, yellow, blue, magenta, cyan, white)")
 	flag.StringVar(focalColor, "c", "red", "Focal point color (shorthand)")
 	showContext := flag.Bool("context", false, "Show surrounding words (prev/next) for context")
+	inlineExtras := flag.Bool("inline-extras", false, "Read footnotes and tables inline in DOCX/ODT documents (default skips them)")
+	format := flag.String("format", "auto", "Input format ("+strings.Join(inputFormats, ", ")+")")
+	skipCode := flag.Bool("skip-code", true, "Skip code blocks in Markdown input")
+	rawHTML := flag.Bool("raw-html", false, "Strip HTML tags instead of extracting the main article")
+	uppercase := flag.Bool("uppercase", false, "Render words in capitals instead of preserving case")
+	renderMode := flag.String("render", "blocks", "Rendering mode ("+strings.Join(renderModes, ", ")+")")
+	fontPath := flag.String("font", "", "FIGlet (.flf) or BDF (.bdf) font file (default built-in block font)")
+	hyphenate := flag.String("hyphenate", "en-us", "Hyphenation patterns for splitting long words ("+hyphenLanguageNames()+", or off)")
+	maxWord := flag.Int("max-word", 16, "Split words longer than this many characters into hyphenated pieces (0 to never split)")
+	keymapSpec := flag.String("keymap", "", "Key bindings: "+strings.Join(keymapPresetNames, ", ")+" or a keymap file (default ~/.config/speedread/keymap if present)")
+	chunk := flag.String("chunk", "1", "Words shown per flash, or smart to join short words to the next")
+	ramp := flag.Duration("ramp", 0, "Warm up from half speed to full speed over this long (e.g. 30s)")
+	intervals := flag.String("intervals", "", "Interval training, repeated in order: duration@wpm,... (e.g. 2m@600,1m@350)")
+	start := flag.String("start", "", "Where to start reading: a word number, 37%, p12 (page), ch5 (chapter) or +200 words")
+	pacing := flag.String("pacing", "length", "How long each word is shown ("+strings.Join(pacingModels, ", ")+")")
 	flag.Parse()
 
+	if !slices.Contains(inputFormats, *format) {
+		fmt.Fprintf(os.Stderr, "Error: unknown format %q (want one of: %s)\n", *format, strings.Join(inputFormats, ", "))
+		os.Exit(1)
+	}
+
+	if !slices.Contains(renderModes, *renderMode) {
+		fmt.Fprintf(os.Stderr, "Error: unknown render mode %q (want one of: %s)\n", *renderMode, strings.Join(renderModes, ", "))
+		os.Exit(1)
+	}
+	if !slices.Contains(pacingModels, *pacing) {
+		fmt.Fprintf(os.Stderr, "Error: unknown pacing model %q (want one of: %s)\n", *pacing, strings.Join(pacingModels, ", "))
+		os.Exit(1)
+	}
+	pacer := newPacer(*pacing)
+	var hyph *hyphenator
+	if *hyphenate != "off" {
+		lang, ok := findHyphenLanguage(*hyphenate)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Error: unknown hyphenation language %q (want one of: %s, off)\n", *hyphenate, hyphenLanguageNames())
+			os.Exit(1)
+		}
+		var err error
+		if hyph, err = loadHyphenator(lang); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	bindings, err := loadKeymap(*keymapSpec)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid keymap: %v\n", err)
+		os.Exit(1)
+	}
+	chunkSize, ok := parseChunk(*chunk)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error: invalid -chunk %q (want a number of words or smart)\n", *chunk)
+		os.Exit(1)
+	}
+
+	var face fontFace = builtinFont{}
+	if *fontPath != "" {
+		loaded, err := loadFont(*fontPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		face = loaded
+	}
+	if *uppercase {
+		face = uppercaseFont{face}
+	}
+
 	// Validate WPM
 	if *wpm < 10 {
 		*wpm = 10
@@ -797,10 +707,25 @@ func main() {
 	if *wpm > 1000 {
 		*wpm = 1000
 	}
+	plan := speedPlan{Base: *wpm, Ramp: *ramp}
+	if *intervals != "" {
+		var err error
+		if plan.Intervals, err = parseIntervals(*intervals); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid -intervals %v\n", err)
+			os.Exit(1)
+		}
+	}
 
 	// Convert focal color to ANSI code
 	focalColorCode := colorToANSI(*focalColor)
 
+	render := renderOptions{
+		Face:           face,
+		Mode:           *renderMode,
+		Focal:          *focal,
+		FocalColorCode: focalColorCode,
+	}
+
 	// Atomic WPM for thread-safe adjustment during reading
 	var currentWPM atomic.Int32
 	currentWPM.Store(int32(*wpm))
@@ -813,26 +738,56 @@ func main() {
 	}
 
 	// Read input
-	text, err := readInput(filename)
+	src, err := readInput(filename, readOptions{
+		Format:       *format,
+		InlineExtras: *inlineExtras,
+		SkipCode:     *skipCode,
+		RawHTML:      *rawHTML,
+	})
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
 	// Tokenize
-	words := tokenizeWords(text)
+	doc := buildDocument(src)
+	doc.splitLongWords(*maxWord, hyph)
+	words := doc.Tokens
 	if len(words) == 0 {
 		fmt.Fprintln(os.Stderr, "Error: no words found in input")
 		os.Exit(1)
 	}
 
-	// Find longest word for uniform font sizing
+	// Find longest word for uniform font sizing, after long words were split
 	maxWordLen := findMaxWordLen(words)
+	chunks := chunker{Size: chunkSize, MaxLen: maxWordLen}
+
+	// Page and section of the current word, for the status line
+	locationStatus := func(i int) string {
+		var status string
+		if page := words[i].Page; page >= 0 {
+			status += fmt.Sprintf(" | p. %d/%d", page+1, len(doc.Pages))
+		}
+		if sec := words[i].Section; sec >= 0 {
+			title := []rune(doc.Sections[sec].Title)
+			if len(title) > 30 {
+				title = append(title[:29], '…')
+			}
+			status += " | " + string(title)
+		}
+		return status
+	}
 
-	// Check for saved bookmark (only for file input)
+	// Start where -start says, or else offer to resume from a saved bookmark
+	// (only for file input)
 	startPosition := 0
-	if filename != "" && !isURL(filename) {
-		savedPos := getBookmark(filename)
+	if *start != "" {
+		if startPosition, err = resolvePosition(*start, doc, 0); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid -start: %v\n", err)
+			os.Exit(1)
+		}
+	} else if filename != "" && !isURL(filename) {
+		savedPos := getBookmark(filename, doc)
 		if savedPos > 0 && savedPos < len(words) {
 			fmt.Printf("Found bookmark at word %d/%d (%.0f%%). Resume? [Y/n] ", savedPos+1, len(words), float64(savedPos)/float64(len(words))*100)
 			var response string
@@ -859,6 +814,25 @@ func main() {
 	}
 	defer term.Restore(int(tty.Fd()), oldState)
 
+	// Image modes draw through a graphics protocol, so ask the terminal what
+	// it supports before key presses are read
+	if *renderMode == "image" || slices.Contains(graphicsProtocols, *renderMode) {
+		render.Graphics = detectGraphics(tty)
+		render.Graphics.FocalColor = focalRGB["red"]
+		if c, ok := focalRGB[strings.ToLower(*focalColor)]; ok {
+			render.Graphics.FocalColor = c
+		}
+		if *renderMode == "image" {
+			// Terminals without image support get the block renderer
+			render.Mode = cmp.Or(render.Graphics.Protocol, "blocks")
+		}
+	}
+
+	// Draw on the alternate screen so nothing is left in the scrollback
+	scr := newScreen(os.Stdout)
+	scr.enter()
+	defer scr.exit()
+
 	// Pause state
 	var paused atomic.Bool
 
@@ -872,207 +846,539 @@ func main() {
 	var totalPauseTime time.Duration
 	var pauseStart time.Time
 
+	// A replayed sentence is read slowly up to this word, -1 when not replaying
+	var replayEnd atomic.Int32
+	replayEnd.Store(-1)
+
+	// Search and command prompts and the help overlay, drawn by the render
+	// loop
+	search := &searchState{start: -1, end: -1}
+	command := &commandLine{}
+	overlay := &overlayState{}
+
+	// The input goroutine asks for a redraw when it changes what is shown
+	// while paused
+	redraw := make(chan struct{}, 1)
+
 	// Goroutine to handle keyboard input
 	go func() {
-		buf := make([]byte, 3)
-		for {
-			n, _ := tty.Read(buf)
-			if n == 0 {
-				continue
+		// jump moves to word to, remembering where reading was for undo
+		var history []int32
+		record := func(from int32) {
+			history = append(history, from)
+			if len(history) > maxHistory {
+				history = history[1:]
+			}
+		}
+		jump := func(to int) {
+			from := currentIndex.Load()
+			to = min(max(to, 0), int(totalWords)-1)
+			if int32(to) == from {
+				return
 			}
+			record(from)
+			replayEnd.Store(-1)
+			currentIndex.Store(int32(to))
+		}
+		requestRedraw := func() {
+			select {
+			case redraw <- struct{}{}:
+			default:
+			}
+		}
 
-			// Check for escape sequence (arrow keys)
-			if n >= 3 && buf[0] == 27 && buf[1] == '[' {
-				switch buf[2] {
-				case 'A': // Up arrow - increase WPM
-					newWPM := currentWPM.Load() + 25
-					if newWPM > 1000 {
-						newWPM = 1000
-					}
-					currentWPM.Store(newWPM)
-				case 'B': // Down arrow - decrease WPM
-					newWPM := currentWPM.Load() - 25
-					if newWPM < 10 {
-						newWPM = 10
-					}
-					currentWPM.Store(newWPM)
-				case 'D': // Left arrow - rewind
-					newIdx := currentIndex.Load() - 1
-					if newIdx < 0 {
-						newIdx = 0
+		// Chapter moves skip over nested headings
+		chapterUnit := chapterOf(doc)
+
+		// While the search prompt is open, keys edit the query and the first
+		// match from where the search began is shown as it is typed
+		index := newSearchIndex(words)
+		var searchFrom int32
+		var lastQuery string
+		var wasPaused bool
+		preview := func() {
+			if start, ok := search.run(index, int(searchFrom), false); ok {
+				currentIndex.Store(int32(start))
+			} else {
+				currentIndex.Store(searchFrom)
+			}
+		}
+		cancelSearch := func() {
+			search.edit(func(s *searchState) bool {
+				s.query = lastQuery
+				return false
+			})
+			search.clear()
+			currentIndex.Store(searchFrom)
+			paused.Store(wasPaused)
+		}
+		editSearch := func(key string) {
+			switch key {
+			case "enter":
+				search.edit(func(s *searchState) bool {
+					if s.query == "" {
+						s.query = lastQuery
 					}
-					currentIndex.Store(newIdx)
-				case 'C': // Right arrow - skip forward
-					newIdx := currentIndex.Load() + 1
-					if newIdx >= totalWords {
-						newIdx = totalWords - 1
+					return false
+				})
+				preview()
+				if v := search.view(); v.Start >= 0 && int32(v.Start) != searchFrom {
+					record(searchFrom)
+					replayEnd.Store(-1)
+				}
+			case "esc", "ctrl+g":
+				cancelSearch()
+			case "ctrl+r":
+				search.edit(func(s *searchState) bool {
+					s.regex = !s.regex
+					return true
+				})
+				preview()
+			default:
+				if v := search.view(); key == "backspace" && v.Query == "" {
+					cancelSearch()
+					return
+				}
+				edited := false
+				search.edit(func(s *searchState) bool {
+					s.query, edited = editText(s.query, key)
+					return true
+				})
+				if edited {
+					preview()
+				}
+			}
+		}
+
+		// The command line goes to a position typed after ":"
+		var cmdFrom int32
+		cancelCommand := func() {
+			command.edit(func(c *commandLine) bool { return false })
+			paused.Store(wasPaused)
+		}
+		editCommand := func(key string) {
+			switch key {
+			case "enter":
+				v := command.view()
+				to, err := resolvePosition(v.Text, doc, int(cmdFrom))
+				command.edit(func(c *commandLine) bool {
+					if err != nil {
+						c.message = err.Error()
 					}
-					currentIndex.Store(newIdx)
+					return false
+				})
+				if err == nil {
+					jump(to)
+					paused.Store(wasPaused)
 				}
-				continue
+			case "esc", "ctrl+g":
+				cancelCommand()
+			default:
+				if v := command.view(); key == "backspace" && v.Text == "" {
+					cancelCommand()
+					return
+				}
+				command.edit(func(c *commandLine) bool {
+					c.text, _ = editText(c.text, key)
+					return true
+				})
 			}
+		}
 
-			// Single character commands
-			if buf[0] == ' ' {
+		// do carries out the named action
+		do := func(name string) {
+			switch name {
+			case "pause":
 				paused.Store(!paused.Load())
-			} else if buf[0] >= '0' && buf[0] <= '9' {
-				// Number keys: jump to percentage (0=0%, 1=10%, ..., 9=90%)
-				percent := int32(buf[0]-'0') * 10
-				newIdx := totalWords * percent / 100
+				if !paused.Load() {
+					search.clear()
+					command.clear()
+				}
+			case "search":
+				searchFrom = currentIndex.Load()
+				wasPaused = paused.Load()
+				paused.Store(true)
+				search.edit(func(s *searchState) bool {
+					lastQuery, s.query = s.query, ""
+					return true
+				})
+				search.clear()
+				command.clear()
+				requestRedraw()
+			case "help", "palette":
+				wasPaused = paused.Load()
+				paused.Store(true)
+				overlay.edit(func(o *overlayState) {
+					o.mode = overlayHelp
+					if name == "palette" {
+						o.mode = overlayPalette
+					}
+					o.query, o.selected, o.scroll = "", 0, 0
+				})
+				requestRedraw()
+			case "goto":
+				cmdFrom = currentIndex.Load()
+				wasPaused = paused.Load()
+				paused.Store(true)
+				command.edit(func(c *commandLine) bool {
+					c.text, c.message = "", ""
+					return true
+				})
+				search.clear()
+				requestRedraw()
+			case "search-next", "search-prev":
+				from := int(currentIndex.Load())
+				backward := name == "search-prev"
+				command.clear()
+				if !backward {
+					from = (from + 1) % int(totalWords)
+				}
+				if start, ok := search.run(index, from, backward); ok {
+					jump(start)
+				}
+				paused.Store(true)
+				requestRedraw()
+			case "wpm+":
+				newWPM := currentWPM.Load() + 25
+				if newWPM > 1000 {
+					newWPM = 1000
+				}
+				currentWPM.Store(newWPM)
+			case "wpm-":
+				newWPM := currentWPM.Load() - 25
+				if newWPM < 10 {
+					newWPM = 10
+				}
+				currentWPM.Store(newWPM)
+			case "back":
+				newIdx := currentIndex.Load() - 1
+				if newIdx < 0 {
+					newIdx = 0
+				}
+				currentIndex.Store(newIdx)
+			case "forward":
+				newIdx := currentIndex.Load() + 1
 				if newIdx >= totalWords {
 					newIdx = totalWords - 1
 				}
 				currentIndex.Store(newIdx)
-			} else if buf[0] == 3 { // Ctrl+C
+			case "start":
+				jump(0)
+			case "end":
+				jump(int(totalWords) - 1)
+			case "jump-0", "jump-1", "jump-2", "jump-3", "jump-4",
+				"jump-5", "jump-6", "jump-7", "jump-8", "jump-9":
+				// Jump to a tenth of the way through (jump-3 = 30%)
+				to, _ := resolvePosition(name[len(name)-1:]+"0%", doc, 0)
+				jump(to)
+			case "back-sentence":
+				jump(prevUnit(words, int(currentIndex.Load()), sentenceOf))
+			case "forward-sentence":
+				jump(nextUnit(words, int(currentIndex.Load()), sentenceOf))
+			case "back-paragraph":
+				jump(prevUnit(words, int(currentIndex.Load()), paragraphOf))
+			case "forward-paragraph":
+				jump(nextUnit(words, int(currentIndex.Load()), paragraphOf))
+			case "prev-chapter":
+				jump(prevUnit(words, int(currentIndex.Load()), chapterUnit))
+			case "next-chapter":
+				jump(nextUnit(words, int(currentIndex.Load()), chapterUnit))
+			case "replay-sentence":
+				// Replay the sentence of the last word read, slowly
+				last := max(int(currentIndex.Load())-1, 0)
+				jump(unitStart(words, last, sentenceOf))
+				replayEnd.Store(int32(unitEnd(words, last, sentenceOf)))
+			case "undo":
+				if len(history) > 0 {
+					replayEnd.Store(-1)
+					currentIndex.Store(history[len(history)-1])
+					history = history[:len(history)-1]
+				}
+			case "cancel":
+				search.clear()
+				command.clear()
+			case "quit":
 				// Save bookmark before exiting
 				if filename != "" && !isURL(filename) {
-					saveBookmark(filename, int(currentIndex.Load()))
+					saveBookmark(filename, doc, int(currentIndex.Load()))
 				}
+				clearGraphics(scr, render.Mode)
+				scr.exit()
 				term.Restore(int(tty.Fd()), oldState)
-				clearScreen()
 				fmt.Print("Interrupted. Position saved.\r\n")
 				os.Exit(0)
 			}
 		}
-	}()
-
-	// Display each word
-	for currentIndex.Load() < totalWords {
-		i := int(currentIndex.Load())
-		word := words[i]
 
-		// Wait while paused
-		if paused.Load() {
-			pauseStart = time.Now()
+		// Help scrolls with the arrow keys, and any other key closes it. The
+		// palette narrows its list as you type and runs the chosen action.
+		closeOverlay := func() {
+			overlay.edit(func(o *overlayState) { o.mode = overlayNone })
+			paused.Store(wasPaused)
 		}
-		for paused.Load() {
-			// Re-read index in case user navigated while paused
-			i = int(currentIndex.Load())
-			word = words[i]
-
-			termWidth, termHeight := getTerminalSize()
-			clearScreen()
-
-			// Show context: previous word (dimmed)
-			if *showContext && i > 0 {
-				prevWord := words[i-1]
-				padding := (termWidth - len(prevWord)) / 2
-				if padding < 0 {
-					padding = 0
+		editOverlay := func(key string) {
+			v := overlay.view()
+			if v.Mode == overlayHelp {
+				switch key {
+				case "up", "down", "pgup", "pgdown":
+					step := map[string]int{"up": -1, "down": 1, "pgup": -10, "pgdown": 10}[key]
+					width, height := getTerminalSize()
+					last := max(len(helpLines(bindings, width))-(height-4), 0)
+					overlay.edit(func(o *overlayState) {
+						o.scroll = min(max(o.scroll+step, 0), last)
+					})
+				default:
+					closeOverlay()
 				}
-				fmt.Printf("%s\033[2m%s\033[0m\r\n", strings.Repeat(" ", padding), prevWord)
+				return
 			}
-
-			lines := renderWord(word, termWidth, termHeight, *focal, focalColorCode, maxWordLen)
-			for _, line := range lines {
-				fmt.Print(line + "\r\n")
+			matches := paletteMatches(v.Query)
+			switch key {
+			case "up", "ctrl+p", "down", "ctrl+n":
+				step := 1
+				if key == "up" || key == "ctrl+p" {
+					step = -1
+				}
+				overlay.edit(func(o *overlayState) {
+					o.selected = min(max(o.selected+step, 0), max(len(matches)-1, 0))
+				})
+			case "enter":
+				if v.Selected < len(matches) {
+					closeOverlay()
+					do(matches[v.Selected].Name)
+				}
+			case "esc", "ctrl+g":
+				closeOverlay()
+			default:
+				overlay.edit(func(o *overlayState) {
+					var edited bool
+					if o.query, edited = editText(o.query, key); edited {
+						o.selected = 0
+					}
+				})
 			}
+		}
 
-			// Show context: next word (dimmed)
-			if *showContext && i < len(words)-1 {
-				nextWord := words[i+1]
-				padding := (termWidth - len(nextWord)) / 2
-				if padding < 0 {
-					padding = 0
-				}
-				fmt.Printf("%s\033[2m%s\033[0m\r\n", strings.Repeat(" ", padding), nextWord)
+		keys := newKeyDecoder(tty)
+		for {
+			key, ok := keys.next()
+			if !ok {
+				return
 			}
 
-			wpmNow := int(currentWPM.Load())
-			remaining := len(words) - i - 1
-			timeLeft := formatTimeRemaining(remaining, wpmNow)
-			progressBar := renderProgressBar(termWidth, i+1, len(words))
-			fmt.Print("\r\n" + progressBar)
-			progress := fmt.Sprintf("\r\n%d WPM | %s left - PAUSED (space, ↑↓, ←→, 0-9)", wpmNow, timeLeft)
-			fmt.Print(progress)
-			time.Sleep(100 * time.Millisecond)
-		}
-		if !pauseStart.IsZero() {
-			totalPauseTime += time.Since(pauseStart)
-			pauseStart = time.Time{}
+			switch {
+			case key == hardQuitKey:
+				do("quit")
+			case overlay.view().Mode != overlayNone:
+				editOverlay(key)
+			case search.view().Editing:
+				editSearch(key)
+			case command.view().Editing:
+				editCommand(key)
+			default:
+				do(bindings[key])
+				continue
+			}
+			requestRedraw()
 		}
+	}()
 
-		// Re-read index in case user navigated
-		i = int(currentIndex.Load())
-		word = words[i]
+	// Size words for the terminal, and again whenever it is resized
+	termWidth, termHeight := getTerminalSize()
+	lay := newLayout(termWidth, termHeight, maxWordLen, render.Face)
+	resized := watchResize()
+
+	// Words are due a set time after the last, on a schedule that also
+	// measures reading time for the speed plan
+	var sched scheduler
+
+	// speed returns the reading speed for word i and the phase to show with
+	// it: replayed sentences go slower than the speed plan
+	speed := func(i int) (int, string) {
+		wpm, phase := plan.at(int(currentWPM.Load()), sched.elapsed)
+		if end := replayEnd.Load(); end >= 0 && int32(i) < end {
+			return max(int(float64(wpm)*replaySpeed), 10), "replay"
+		}
+		return wpm, phase
+	}
 
-		termWidth, termHeight := getTerminalSize()
-		clearScreen()
+	// frame builds the lines shown for the flash starting at word i: context,
+	// the words themselves and the progress display
+	frame := func(i int, status string) []string {
+		switch v := overlay.view(); v.Mode {
+		case overlayHelp:
+			return helpFrame(bindings, v.Scroll, lay.Width, lay.Height)
+		case overlayPalette:
+			return paletteFrame(bindings, v, lay.Width, lay.Height)
+		}
+		termWidth := lay.Width
+		end := chunks.end(words, i)
+		var lines []string
 
 		// Show context: previous word (dimmed)
 		if *showContext && i > 0 {
-			prevWord := words[i-1]
+			prevWord := words[i-1].Text
 			padding := (termWidth - len(prevWord)) / 2
 			if padding < 0 {
 				padding = 0
 			}
-			fmt.Printf("%s\033[2m%s\033[0m\r\n", strings.Repeat(" ", padding), prevWord)
+			lines = append(lines, fmt.Sprintf("%s\033[2m%s\033[0m", strings.Repeat(" ", padding), prevWord))
 		}
 
-		// Render and display the word
-		lines := renderWord(word, termWidth, termHeight, *focal, focalColorCode, maxWordLen)
-		for _, line := range lines {
-			fmt.Print(line + "\r\n")
-		}
+		// Render the words
+		lines = append(lines, renderWord(chunkText(words, i, end), lay, render)...)
 
 		// Show context: next word (dimmed)
-		if *showContext && i < len(words)-1 {
-			nextWord := words[i+1]
+		if *showContext && end < len(words) {
+			nextWord := words[end].Text
 			padding := (termWidth - len(nextWord)) / 2
 			if padding < 0 {
 				padding = 0
 			}
-			fmt.Printf("%s\033[2m%s\033[0m\r\n", strings.Repeat(" ", padding), nextWord)
+			lines = append(lines, fmt.Sprintf("%s\033[2m%s\033[0m", strings.Repeat(" ", padding), nextWord))
 		}
 
-		// Show progress at bottom
-		wpmNow := currentWPM.Load()
+		// Show a search match in the words around it
+		found := search.view()
+		if found.Start >= i && found.Start < end {
+			lines = append(lines, "", found.contextLine(words, termWidth))
+		}
+
+		// Show progress at bottom, or the search prompt while it is open
+		wpmNow, phase := speed(i)
+		if phase != "" {
+			phase = " (" + phase + ")"
+		}
 		remaining := len(words) - i - 1
-		timeLeft := formatTimeRemaining(remaining, int(wpmNow))
+		timeLeft := formatTimeRemaining(remaining, wpmNow)
 		progressBar := renderProgressBar(termWidth, i+1, len(words))
-		fmt.Print("\r\n" + progressBar)
-		progress := fmt.Sprintf("\r\n%d WPM | %s left - Space, ↑↓, ←→, 0-9 jump", wpmNow, timeLeft)
-		fmt.Print(progress)
-
-		// Calculate delay based on current WPM with variable timing for word length
-		baseDelay := float64(time.Minute) / float64(wpmNow)
-		// Add 8% extra time per character above average length (5 chars)
-		wordLen := len([]rune(word))
-		if wordLen > 5 {
-			extraChars := wordLen - 5
-			baseDelay *= 1.0 + (float64(extraChars) * 0.08)
+		cmd := command.view()
+		if found.Message != "" {
+			status = found.Message
+		}
+		if cmd.Message != "" {
+			status = cmd.Message
+		}
+		progress := fmt.Sprintf("%d WPM%s | %s left%s - %s", wpmNow, phase, timeLeft, locationStatus(i), status)
+		switch {
+		case found.Editing:
+			progress = found.prompt()
+		case cmd.Editing:
+			progress = cmd.prompt()
+		}
+		return append(lines, "", progressBar, progress)
+	}
+	// Kitty keeps images under text drawn over them, so the word's image
+	// is removed when an overlay opens
+	overlaid := false
+	show := func(i int, status string) {
+		open := overlay.view().Mode != overlayNone
+		if open && !overlaid {
+			clearGraphics(scr, render.Mode)
+		}
+		overlaid = open
+		scr.draw(frame(i, status), lay.Width, lay.Height)
+	}
+
+	// wait sleeps for d while word i is shown, reporting whether all of d
+	// passed. A resize meanwhile lays the word out again for the new size and
+	// redraws it straight away. A redraw asked for while paused ends the wait
+	// early, so the paused loop shows the word the input moved to.
+	wait := func(d time.Duration, i int, status string) bool {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		for {
+			select {
+			case <-timer.C:
+				return true
+			case <-resized:
+				termWidth, termHeight := getTerminalSize()
+				lay = newLayout(termWidth, termHeight, maxWordLen, render.Face)
+				scr.invalidate()
+				show(i, status)
+			case <-redraw:
+				if paused.Load() {
+					return false
+				}
+				show(i, status)
+			}
 		}
-		delay := time.Duration(baseDelay)
-		time.Sleep(delay)
+	}
+
+	// Remind the reader of the keys for pausing and help in the active keymap
+	readingStatus := bindings.hint()
+	pausedStatus := "PAUSED"
+	if readingStatus != "" {
+		pausedStatus += " (" + readingStatus + ")"
+	}
+
+	// Display each word
+	sched.start(time.Now())
+	for currentIndex.Load() < totalWords {
+		i := int(currentIndex.Load())
 
-		// Add automatic pause at sentence boundaries (. ! ?)
-		if endsWithSentence(word) {
-			time.Sleep(150 * time.Millisecond)
+		// Wait while paused
+		if paused.Load() {
+			pauseStart = time.Now()
 		}
+		for paused.Load() {
+			// Re-read index in case user navigated while paused
+			i = int(currentIndex.Load())
 
-		// Add extra pause after other punctuation (user-configured)
-		if *punctPause > 0 && endsWithPunctuation(word) && !endsWithSentence(word) {
-			time.Sleep(time.Duration(*punctPause) * time.Millisecond)
+			show(i, pausedStatus)
+			wait(100*time.Millisecond, i, pausedStatus)
 		}
+		if !pauseStart.IsZero() {
+			totalPauseTime += time.Since(pauseStart)
+			pauseStart = time.Time{}
+			sched.start(time.Now())
+		}
+
+		// Re-read index in case user navigated
+		i = int(currentIndex.Load())
 
-		// Advance to next word (if not navigated away)
-		currentIndex.CompareAndSwap(int32(i), int32(i+1))
+		show(i, readingStatus)
+		wpmNow, _ := speed(i)
+		end := chunks.end(words, i)
+
+		// Calculate delay based on current WPM, timed by the pacing model
+		// for each word of the flash
+		var delay time.Duration
+		for w := i; w < end; w++ {
+			delay += pacer.Delay(words, w, wpmNow)
+		}
+
+		// Add the pause for the punctuation or boundary the flash ends
+		delay += pauses.after(words, end-1, time.Duration(wordTime(wpmNow)))
+
+		if !wait(time.Until(sched.next(delay)), i, readingStatus) {
+			// Paused before the words were due: they are shown again on
+			// resuming rather than counted as read, timed from then
+			sched.start(time.Now())
+			continue
+		}
+		sched.arrived(time.Now(), countWords(words[i:end]))
+
+		// Advance to the next flash (if not navigated away), ending a replay
+		// once its sentence has been read
+		if currentIndex.CompareAndSwap(int32(i), int32(end)) {
+			if replay := replayEnd.Load(); replay >= 0 && int32(end) >= replay {
+				replayEnd.CompareAndSwap(replay, -1)
+			}
+		}
 	}
 
 	// Clear bookmark since reading is complete
 	if filename != "" && !isURL(filename) {
-		saveBookmark(filename, 0) // 0 removes the bookmark
+		saveBookmark(filename, doc, 0) // 0 removes the bookmark
 	}
 
-	// Final clear and session statistics
-	clearScreen()
+	// Back on the normal screen for the session statistics
+	clearGraphics(scr, render.Mode)
+	scr.exit()
 	sessionDuration := time.Since(sessionStart)
 	activeTime := sessionDuration - totalPauseTime
-	wordsRead := len(words)
-	actualWPM := 0
-	if activeTime.Minutes() > 0 {
-		actualWPM = int(float64(wordsRead) / activeTime.Minutes())
-	}
+	wordsRead := countWords(words)
 
 	fmt.Print("Session Complete!\r\n")
 	fmt.Print("─────────────────\r\n")
@@ -1080,5 +1386,8 @@ func main() {
 	fmt.Printf("Total time:    %s\r\n", sessionDuration.Round(time.Second))
 	fmt.Printf("Time paused:   %s\r\n", totalPauseTime.Round(time.Second))
 	fmt.Printf("Active time:   %s\r\n", activeTime.Round(time.Second))
-	fmt.Printf("Actual WPM:    %d\r\n", actualWPM)
+	fmt.Printf("Target WPM:    %d\r\n", sched.targetWPM())
+	fmt.Printf("Achieved WPM:  %d\r\n", sched.achievedWPM())
+	fmt.Printf("Drift:         %s\r\n", sched.drift().Round(time.Millisecond))
+	fmt.Printf("Jitter:        ±%s\r\n", sched.jitter().Round(100*time.Microsecond))
 }
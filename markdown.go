@@ -0,0 +1,260 @@
+package main
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	mdATXHeading   = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	mdSetextLine   = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	mdFence        = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+	mdRule         = regexp.MustCompile(`^ {0,3}([-*_])(?:[ \t]*[-*_]){2,}[ \t]*$`)
+	mdListMarker   = regexp.MustCompile(`^[ \t]*(?:[-*+]|\d{1,9}[.)])[ \t]+(?:\[[ xX]\][ \t]+)?`)
+	mdQuoteMarker  = regexp.MustCompile(`^ {0,3}>[ ]?`)
+	mdTableDivider = regexp.MustCompile(`^[ \t]*\|?[ \t]*:?-+:?[ \t]*(\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$`)
+	mdLinkDef      = regexp.MustCompile(`^ {0,3}\[[^\]]+\]:[ \t]*\S+`)
+	mdHTMLComment  = regexp.MustCompile(`(?s)<!--.*?-->`)
+
+	mdImage     = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)|!\[([^\]]*)\]\[[^\]]*\]`)
+	mdLink      = regexp.MustCompile(`\[([^\]]*)\]\((?:[^()]|\([^)]*\))*\)|\[([^\]]*)\]\[[^\]]*\]`)
+	mdFootnote  = regexp.MustCompile(`\[\^[^\]]+\]`)
+	mdAutolink  = regexp.MustCompile(`<((?:https?|ftp|mailto):[^>\s]+)>`)
+	mdHTMLTag   = regexp.MustCompile(`</?[A-Za-z][A-Za-z0-9-]*(?:\s[^<>]*)?/?>`)
+	mdCodeSpan  = regexp.MustCompile("(`+)(.+?)(`+)")
+	mdStrong    = regexp.MustCompile(`(\*\*|__)(\S(?:.*?\S)?)(\*\*|__)`)
+	mdEmphStar  = regexp.MustCompile(`\*(\S(?:[^*]*?\S)?)\*`)
+	mdEmphUnder = regexp.MustCompile(`(^|[^\pL\pN_])_(\S(?:[^_]*?\S)?)_($|[^\pL\pN_])`)
+	mdStrike    = regexp.MustCompile(`~~(\S(?:.*?\S)?)~~`)
+	mdBackslash = regexp.MustCompile("\\\\([!\"#$%&'()*+,\\-./:;<=>?@\\[\\\\\\]^_`{|}~])")
+)
+
+// Placeholders for backslash-escaped characters and code spans while inline
+// syntax is stripped
+const (
+	mdEscaped     = "\x00"
+	mdCode        = "\x01"
+	mdEscapedTick = "\x02"
+)
+
+// isMarkdownFile reports whether a filename has a Markdown extension
+func isMarkdownFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".md", ".markdown", ".mdown", ".mkd", ".mkdn":
+		return true
+	}
+	return false
+}
+
+// readMarkdown renders Markdown to plain prose: syntax is stripped, links
+// show only their text, headings become sections and code blocks are
+// dropped when opts.SkipCode is set.
+func readMarkdown(text string, opts readOptions) source {
+	lines := strings.Split(strings.ReplaceAll(mdHTMLComment.ReplaceAllString(text, ""), "\r\n", "\n"), "\n")
+	lines = skipFrontMatter(lines)
+
+	w := newDocWriter()
+	var para []string
+	flush := func(headingLevel int) {
+		if len(para) == 0 {
+			return
+		}
+		w.startParagraph(headingLevel)
+		w.text(renderMarkdownInline(strings.Join(para, " ")))
+		w.endParagraph()
+		para = para[:0]
+	}
+
+	// Code is read verbatim, without stripping inline syntax
+	var code []string
+	flushCode := func() {
+		if len(code) == 0 {
+			return
+		}
+		w.startParagraph(-1)
+		w.text(strings.Join(code, " "))
+		w.endParagraph()
+		code = code[:0]
+	}
+
+	var fence string // Open code fence marker, if inside a fenced block
+	listIndent := -1 // Column the open list item's text starts at, -1 outside lists
+	for _, line := range lines {
+		if fence != "" {
+			if closesFence(line, fence) {
+				fence = ""
+				flushCode()
+			} else if !opts.SkipCode {
+				code = append(code, line)
+			}
+			continue
+		}
+		if m := mdFence.FindStringSubmatch(line); m != nil {
+			flush(-1)
+			fence = m[1]
+			continue
+		}
+
+		// Blockquote markers are stripped; quoted text is read as normal text
+		for mdQuoteMarker.MatchString(line) {
+			line = mdQuoteMarker.ReplaceAllString(line, "")
+		}
+		trimmed := strings.TrimSpace(line)
+
+		// Indented lines go on with an open list item, unless they are
+		// indented past its text as far as a code block would be
+		indent := indentWidth(line)
+		if listIndent >= 0 && trimmed != "" && indent < listIndent && len(para) == 0 {
+			listIndent = -1
+		}
+		codeLine := isIndentedCode(line) && (listIndent < 0 || indent >= listIndent+4)
+
+		if !codeLine || len(para) > 0 {
+			flushCode()
+		}
+
+		switch {
+		case trimmed == "":
+			flush(-1)
+		case codeLine && len(para) == 0:
+			if !opts.SkipCode {
+				code = append(code, trimmed)
+			}
+		case mdATXHeading.MatchString(line):
+			flush(-1)
+			m := mdATXHeading.FindStringSubmatch(line)
+			para = append(para, m[2])
+			flush(len(m[1]) - 1)
+			listIndent = -1
+		case len(para) > 0 && mdSetextLine.MatchString(line) && !mdListMarker.MatchString(para[0]):
+			level := 0
+			if strings.HasPrefix(trimmed, "-") {
+				level = 1
+			}
+			flush(level)
+		case mdRule.MatchString(line):
+			flush(-1)
+			listIndent = -1
+		case mdLinkDef.MatchString(line) && len(para) == 0:
+			// Reference-style link definitions are not part of the prose
+		case mdTableDivider.MatchString(line) && strings.Contains(line, "-") && strings.Contains(line, "|"):
+		case mdListMarker.MatchString(line):
+			flush(-1)
+			listIndent = len(mdListMarker.FindString(line))
+			para = append(para, mdListMarker.ReplaceAllString(line, ""))
+		case strings.HasPrefix(trimmed, "|"):
+			// Table rows are read cell by cell, one paragraph per row
+			flush(-1)
+			cells := strings.Split(strings.Trim(trimmed, "|"), "|")
+			for j := range cells {
+				cells[j] = strings.TrimSpace(cells[j])
+			}
+			para = append(para, strings.Join(cells, " "))
+			flush(-1)
+		default:
+			para = append(para, trimmed)
+		}
+	}
+	flush(-1)
+	flushCode()
+
+	return w.source()
+}
+
+// skipFrontMatter drops a leading YAML or TOML front matter block
+func skipFrontMatter(lines []string) []string {
+	if len(lines) == 0 {
+		return lines
+	}
+	delim := strings.TrimSpace(lines[0])
+	if delim != "---" && delim != "+++" {
+		return lines
+	}
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == delim {
+			return lines[i+1:]
+		}
+	}
+	return lines
+}
+
+func isIndentedCode(line string) bool {
+	return strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t")
+}
+
+// indentWidth is the column the text of line starts at, with tab stops every
+// four columns
+func indentWidth(line string) int {
+	width := 0
+	for _, r := range line {
+		switch r {
+		case ' ':
+			width++
+		case '\t':
+			width += 4 - width%4
+		default:
+			return width
+		}
+	}
+	return width
+}
+
+// closesFence reports whether line ends a code block opened with fence: a
+// run of the same character at least as long, with nothing after it
+func closesFence(line, fence string) bool {
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 {
+		return false
+	}
+	run := len(trimmed) - len(strings.TrimLeft(trimmed, fence[:1]))
+	return run >= len(fence) && strings.TrimSpace(trimmed[run:]) == ""
+}
+
+// renderMarkdownInline strips inline Markdown syntax, keeping only the
+// text a reader would see
+func renderMarkdownInline(s string) string {
+	// Code spans are set aside first, as nothing inside them is an escape or
+	// emphasis; an escaped backtick doesn't start one
+	s = strings.ReplaceAll(s, "\\`", mdEscapedTick)
+	var code []string
+	s = mdCodeSpan.ReplaceAllStringFunc(s, func(m string) string {
+		sub := mdCodeSpan.FindStringSubmatch(m)
+		if sub[1] != sub[3] {
+			return m
+		}
+		code = append(code, strings.TrimSpace(sub[2]))
+		return mdCode
+	})
+
+	// Protect escaped characters from the emphasis rules below
+	var escaped []string
+	s = mdBackslash.ReplaceAllStringFunc(s, func(m string) string {
+		escaped = append(escaped, m[1:])
+		return mdEscaped
+	})
+	s = mdImage.ReplaceAllString(s, "$1$2")
+	s = mdLink.ReplaceAllString(s, "$1$2")
+	s = mdFootnote.ReplaceAllString(s, "")
+	s = mdAutolink.ReplaceAllString(s, "$1")
+	s = mdHTMLTag.ReplaceAllString(s, "")
+	// Emphasis can nest, so strip until nothing changes
+	for {
+		next := mdStrong.ReplaceAllString(s, "$2")
+		next = mdStrike.ReplaceAllString(next, "$1")
+		next = mdEmphStar.ReplaceAllString(next, "$1")
+		next = mdEmphUnder.ReplaceAllString(next, "$1$2$3")
+		if next == s {
+			break
+		}
+		s = next
+	}
+
+	for _, e := range escaped {
+		s = strings.Replace(s, mdEscaped, e, 1)
+	}
+	for _, c := range code {
+		s = strings.Replace(s, mdCode, c, 1)
+	}
+	return strings.ReplaceAll(s, mdEscapedTick, "`")
+}
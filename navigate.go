@@ -0,0 +1,71 @@
+package main
+
+// Units of text that navigation moves by, keyed by what each token records
+var (
+	sentenceOf  = func(t token) int { return t.Sentence }
+	paragraphOf = func(t token) int { return t.Paragraph }
+)
+
+// chapterOf returns the unit for moving by chapter in doc, numbering each
+// token by the chapter it is in, -1 before the first
+func chapterOf(doc *document) func(token) int {
+	chapter := make([]int, len(doc.Sections))
+	chapters := doc.chapters()
+	n := -1
+	for i := range chapter {
+		if n+1 < len(chapters) && chapters[n+1] == i {
+			n++
+		}
+		chapter[i] = n
+	}
+	return func(t token) int {
+		if t.Section < 0 {
+			return -1
+		}
+		return chapter[t.Section]
+	}
+}
+
+// Fraction of the reading speed a replayed sentence is read at
+const replaySpeed = 0.7
+
+// Most jumps remembered for undo
+const maxHistory = 100
+
+// unitStart returns the index of the first word of the unit containing
+// word i
+func unitStart(words []token, i int, unit func(token) int) int {
+	for i > 0 && unit(words[i-1]) == unit(words[i]) {
+		i--
+	}
+	return i
+}
+
+// unitEnd returns the index after the last word of the unit containing
+// word i
+func unitEnd(words []token, i int, unit func(token) int) int {
+	j := i + 1
+	for j < len(words) && unit(words[j]) == unit(words[i]) {
+		j++
+	}
+	return j
+}
+
+// prevUnit returns the start of the unit containing word i, or of the
+// unit before when i is already at its start
+func prevUnit(words []token, i int, unit func(token) int) int {
+	start := unitStart(words, i, unit)
+	if start == i && i > 0 {
+		start = unitStart(words, i-1, unit)
+	}
+	return start
+}
+
+// nextUnit returns the start of the unit after the one containing word i,
+// staying at i if it is in the last unit
+func nextUnit(words []token, i int, unit func(token) int) int {
+	if end := unitEnd(words, i, unit); end < len(words) {
+		return end
+	}
+	return i
+}
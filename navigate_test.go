@@ -0,0 +1,89 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+// A README-style document: one title over several chapters, one of them
+// with a subheading
+const testReadme = `# Project
+
+Intro text.
+
+## Install
+
+Run the installer.
+
+## Usage
+
+Read a file.
+
+### Flags
+
+Pass a flag.
+
+## License
+
+MIT.
+`
+
+// wordIndex returns the index of the first token with text, failing the
+// test if there is none
+func wordIndex(t *testing.T, doc *document, text string) int {
+	t.Helper()
+	for i, tok := range doc.Tokens {
+		if tok.Text == text {
+			return i
+		}
+	}
+	t.Fatalf("no word %q", text)
+	return -1
+}
+
+func TestChapters(t *testing.T) {
+	tests := []struct {
+		name   string
+		levels []int
+		want   []int
+	}{
+		{"title over chapters", []int{0, 1, 1, 2, 1}, []int{1, 2, 4}},
+		{"top-level chapters", []int{0, 1, 0, 1, 0}, []int{0, 2, 4}},
+		{"only subheadings", []int{1, 2, 1}, []int{0, 2}},
+		{"a single heading", []int{2}, []int{0}},
+		{"no headings", nil, nil},
+	}
+	for _, tt := range tests {
+		doc := &document{}
+		for _, level := range tt.levels {
+			doc.Sections = append(doc.Sections, docSection{Level: level})
+		}
+		if got := doc.chapters(); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: chapters() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestChapterNavigation(t *testing.T) {
+	doc := buildDocument(readMarkdown(testReadme, readOptions{}))
+	unit := chapterOf(doc)
+	install := wordIndex(t, doc, "Install")
+	usage := wordIndex(t, doc, "Usage")
+	flags := wordIndex(t, doc, "Flags")
+	license := wordIndex(t, doc, "License")
+
+	// ] moves chapter by chapter past the subheading, [ moves back
+	next := []int{nextUnit(doc.Tokens, 0, unit)}
+	for len(next) < 3 {
+		next = append(next, nextUnit(doc.Tokens, next[len(next)-1], unit))
+	}
+	if want := []int{install, usage, license}; !slices.Equal(next, want) {
+		t.Errorf("next chapters = %v, want %v", next, want)
+	}
+	if got := prevUnit(doc.Tokens, flags, unit); got != usage {
+		t.Errorf("back from the subheading = %d, want %d", got, usage)
+	}
+	if got := prevUnit(doc.Tokens, usage, unit); got != install {
+		t.Errorf("back from a chapter start = %d, want %d", got, install)
+	}
+}
@@ -0,0 +1,377 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// isDOCX reports whether the zip archive is a Word document
+func isDOCX(zr *zip.Reader) bool {
+	return zipFile(zr, "word/document.xml") != nil
+}
+
+// isODT reports whether the zip archive is an OpenDocument text document
+func isODT(zr *zip.Reader) bool {
+	mimetype, err := readZipFile(zr, "mimetype")
+	if err == nil {
+		return strings.TrimSpace(string(mimetype)) == "application/vnd.oasis.opendocument.text"
+	}
+	return zipFile(zr, "content.xml") != nil
+}
+
+// docWriter builds the text of a word processor document, turning
+// headings into sections
+type docWriter struct {
+	t            textBuilder
+	sections     []section
+	headingStart int
+	headingLevel int // -1 when the current paragraph is not a heading
+	noteDepth    int // Inside an inline footnote, where paragraphs run together
+}
+
+func newDocWriter() *docWriter {
+	return &docWriter{t: textBuilder{anchors: make(map[string]int)}, headingLevel: -1}
+}
+
+func (w *docWriter) startParagraph(headingLevel int) {
+	if w.noteDepth > 0 {
+		// Paragraphs of an inline note run together
+		if !strings.HasSuffix(w.t.sb.String(), "[") {
+			w.t.space = true
+		}
+		return
+	}
+	w.t.paragraphBreak()
+	w.headingLevel = headingLevel
+	w.headingStart = w.t.Len()
+}
+
+func (w *docWriter) endParagraph() {
+	if w.noteDepth > 0 {
+		return
+	}
+	if w.headingLevel >= 0 {
+		title := strings.TrimSpace(w.t.sb.String()[w.headingStart:])
+		if title != "" {
+			w.sections = append(w.sections, section{Title: title, Offset: w.headingStart, Level: w.headingLevel})
+		}
+		w.headingLevel = -1
+	}
+	w.t.paragraphBreak()
+}
+
+func (w *docWriter) text(s string) {
+	w.t.writeInline(s)
+}
+
+// writeRaw writes s without collapsing whitespace, used for text that
+// spans several runs of the same word
+func (w *docWriter) writeRaw(s string) {
+	if s == "" {
+		return
+	}
+	if w.t.space && w.t.Len() > 0 && !w.t.atLineStart() {
+		w.t.sb.WriteByte(' ')
+		w.t.space = false
+	}
+	for i, f := range strings.Split(s, " ") {
+		if i > 0 {
+			w.t.space = true
+		}
+		if f != "" {
+			if w.t.space && w.t.Len() > 0 && !w.t.atLineStart() {
+				w.t.sb.WriteByte(' ')
+			}
+			w.t.space = false
+			w.t.sb.WriteString(f)
+		}
+	}
+}
+
+func (w *docWriter) source() source {
+	return source{Text: w.t.String(), Sections: w.sections}
+}
+
+func attr(se xml.StartElement, local string) string {
+	for _, a := range se.Attr {
+		if a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// Runs of whitespace collapse to a single space in OpenDocument text
+var odtWhitespace = regexp.MustCompile(`[ \t\r\n]+`)
+
+var headingStyleName = regexp.MustCompile(`(?i)^heading\s*(\d)$`)
+
+// readDOCX extracts paragraph text from word/document.xml. Footnotes,
+// endnotes and tables are read inline or skipped depending on opts.
+func readDOCX(zr *zip.Reader, opts readOptions) (source, error) {
+	data, err := readZipFile(zr, "word/document.xml")
+	if err != nil {
+		return source{}, fmt.Errorf("invalid DOCX: %w", err)
+	}
+	headingLevels := docxHeadingStyles(zr)
+	var notes map[string]string
+	if opts.InlineExtras {
+		notes = docxNotes(zr, "word/footnotes.xml", "footnote")
+		for id, text := range docxNotes(zr, "word/endnotes.xml", "endnote") {
+			notes["e"+id] = text
+		}
+	}
+
+	w := newDocWriter()
+	d := xml.NewDecoder(bytes.NewReader(data))
+	inText := false
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return source{}, fmt.Errorf("invalid DOCX: %w", err)
+		}
+		switch el := tok.(type) {
+		case xml.StartElement:
+			switch el.Name.Local {
+			case "p":
+				w.startParagraph(-1)
+			case "pStyle":
+				if level, ok := headingLevels[attr(el, "val")]; ok {
+					w.headingLevel = level
+				}
+			case "outlineLvl":
+				if level, err := strconv.Atoi(attr(el, "val")); err == nil && level < 9 {
+					w.headingLevel = level
+				}
+			case "t":
+				inText = true
+			case "tab":
+				w.text(" ")
+			case "br", "cr":
+				w.t.lineBreak()
+			case "tbl":
+				if !opts.InlineExtras {
+					d.Skip()
+				}
+			case "footnoteReference", "endnoteReference":
+				id := attr(el, "id")
+				if el.Name.Local == "endnoteReference" {
+					id = "e" + id
+				}
+				if note := notes[id]; note != "" {
+					w.text(" [" + note + "]")
+				}
+			case "Fallback", "instrText", "delText", "pPrChange", "rPrChange":
+				// Alternate content duplicates the Choice branch; field codes and
+				// tracked deletions are not part of the visible text
+				d.Skip()
+			}
+		case xml.EndElement:
+			switch el.Name.Local {
+			case "p":
+				w.endParagraph()
+			case "t":
+				inText = false
+			}
+		case xml.CharData:
+			if inText {
+				w.writeRaw(string(el))
+			}
+		}
+	}
+
+	src := w.source()
+	if strings.TrimSpace(src.Text) == "" {
+		return source{}, errors.New("DOCX contains no readable text")
+	}
+	return src, nil
+}
+
+// docxHeadingStyles maps paragraph style ids to heading levels using
+// styles.xml, so localized style names ("Überschrift 1") are recognized
+func docxHeadingStyles(zr *zip.Reader) map[string]int {
+	levels := map[string]int{"Title": 0}
+	for i := 1; i <= 9; i++ {
+		levels["Heading"+strconv.Itoa(i)] = i - 1
+	}
+	data, err := readZipFile(zr, "word/styles.xml")
+	if err != nil {
+		return levels
+	}
+
+	var styles struct {
+		Styles []struct {
+			ID   string `xml:"styleId,attr"`
+			Name struct {
+				Val string `xml:"val,attr"`
+			} `xml:"name"`
+			OutlineLvl *struct {
+				Val string `xml:"val,attr"`
+			} `xml:"pPr>outlineLvl"`
+		} `xml:"style"`
+	}
+	if xml.Unmarshal(data, &styles) != nil {
+		return levels
+	}
+	for _, s := range styles.Styles {
+		if m := headingStyleName.FindStringSubmatch(s.Name.Val); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			levels[s.ID] = n - 1
+		} else if strings.EqualFold(s.Name.Val, "title") {
+			levels[s.ID] = 0
+		} else if s.OutlineLvl != nil {
+			if n, err := strconv.Atoi(s.OutlineLvl.Val); err == nil && n < 9 {
+				levels[s.ID] = n
+			}
+		}
+	}
+	return levels
+}
+
+// docxNotes reads the text of every footnote or endnote, keyed by id
+func docxNotes(zr *zip.Reader, name, element string) map[string]string {
+	notes := make(map[string]string)
+	data, err := readZipFile(zr, name)
+	if err != nil {
+		return notes
+	}
+
+	d := xml.NewDecoder(bytes.NewReader(data))
+	var id string
+	var text strings.Builder
+	inText := false
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			break
+		}
+		switch el := tok.(type) {
+		case xml.StartElement:
+			switch el.Name.Local {
+			case element:
+				id = attr(el, "id")
+				text.Reset()
+				// Separator "notes" only hold the rule above the notes area
+				if t := attr(el, "type"); t != "" && t != "normal" {
+					d.Skip()
+					id = ""
+				}
+			case "t":
+				inText = true
+			case "p", "tab":
+				text.WriteByte(' ')
+			}
+		case xml.EndElement:
+			switch el.Name.Local {
+			case element:
+				if id != "" {
+					notes[id] = strings.Join(strings.Fields(text.String()), " ")
+				}
+			case "t":
+				inText = false
+			}
+		case xml.CharData:
+			if inText {
+				text.Write(el)
+			}
+		}
+	}
+	return notes
+}
+
+// readODT extracts paragraph text from content.xml. Notes and tables are
+// read inline or skipped depending on opts.
+func readODT(zr *zip.Reader, opts readOptions) (source, error) {
+	data, err := readZipFile(zr, "content.xml")
+	if err != nil {
+		return source{}, fmt.Errorf("invalid ODT: %w", err)
+	}
+
+	w := newDocWriter()
+	d := xml.NewDecoder(bytes.NewReader(data))
+	inBody := false
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return source{}, fmt.Errorf("invalid ODT: %w", err)
+		}
+		switch el := tok.(type) {
+		case xml.StartElement:
+			if el.Name.Local == "body" {
+				inBody = true
+			}
+			if !inBody {
+				continue
+			}
+			switch el.Name.Local {
+			case "p":
+				w.startParagraph(-1)
+			case "h":
+				level := 0
+				if n, err := strconv.Atoi(attr(el, "outline-level")); err == nil && n > 0 {
+					level = n - 1
+				}
+				w.startParagraph(level)
+			case "s":
+				count := 1
+				if n, err := strconv.Atoi(attr(el, "c")); err == nil && n > 0 {
+					count = n
+				}
+				w.writeRaw(strings.Repeat(" ", count))
+			case "tab":
+				w.text(" ")
+			case "line-break":
+				w.t.lineBreak()
+			case "table":
+				if !opts.InlineExtras {
+					d.Skip()
+				}
+			case "note":
+				if !opts.InlineExtras {
+					d.Skip()
+					continue
+				}
+				w.text(" [")
+				w.noteDepth++
+			case "note-citation", "tracked-changes":
+				d.Skip()
+			}
+		case xml.EndElement:
+			if !inBody {
+				continue
+			}
+			switch el.Name.Local {
+			case "p", "h":
+				w.endParagraph()
+			case "note":
+				w.noteDepth--
+				w.writeRaw("]")
+			case "body":
+				inBody = false
+			}
+		case xml.CharData:
+			if inBody {
+				w.writeRaw(odtWhitespace.ReplaceAllString(string(el), " "))
+			}
+		}
+	}
+
+	src := w.source()
+	if strings.TrimSpace(src.Text) == "" {
+		return source{}, errors.New("ODT contains no readable text")
+	}
+	return src, nil
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	_ "embed"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Pacer decides how long each word stays on screen
+type Pacer interface {
+	// Delay is how long words[i] is shown when reading at wpm, before any
+	// pause for punctuation
+	Delay(words []token, i int, wpm int) time.Duration
+}
+
+// Pacing models selectable with -pacing
+var pacingModels = []string{"length", "frequency", "syllable", "custom"}
+
+func newPacer(model string) Pacer {
+	switch model {
+	case "frequency":
+		return frequencyPacer{}
+	case "syllable":
+		return syllablePacer{}
+	case "custom":
+		return customPacer{}
+	default:
+		return lengthPacer{}
+	}
+}
+
+// wordTime is the time one word gets at wpm
+func wordTime(wpm int) float64 {
+	return float64(time.Minute) / float64(max(wpm, 1))
+}
+
+// letters returns the letters and digits of word, without punctuation
+func letters(word string) []rune {
+	var rs []rune
+	for _, r := range word {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			rs = append(rs, r)
+		}
+	}
+	return rs
+}
+
+// lengthPacer gives long words more time: 8% extra per character above
+// average length (5 chars)
+type lengthPacer struct{}
+
+func (lengthPacer) Delay(words []token, i int, wpm int) time.Duration {
+	return time.Duration(wordTime(wpm) * lengthFactor(words[i].Text))
+}
+
+func lengthFactor(word string) float64 {
+	wordLen := len([]rune(word))
+	if wordLen <= 5 {
+		return 1
+	}
+	return 1 + float64(wordLen-5)*0.08
+}
+
+//go:embed wordfreq.txt
+var wordfreqData string
+
+// Zipf frequency of common words, read from wordfreqData on first use
+var wordZipf = sync.OnceValue(func() map[string]float64 {
+	zipf := make(map[string]float64)
+	for line := range strings.Lines(wordfreqData) {
+		w, z, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok || strings.HasPrefix(w, "#") {
+			continue
+		}
+		if f, err := strconv.ParseFloat(z, 64); err == nil {
+			zipf[w] = f
+		}
+	}
+	return zipf
+})
+
+const (
+	rareZipf    = 3.5 // Assumed for words missing from the list
+	typicalZipf = 5.0 // Words this common get the plain length delay
+)
+
+func zipfFrequency(word string) float64 {
+	if z, ok := wordZipf()[strings.ToLower(string(letters(word)))]; ok {
+		return z
+	}
+	return rareZipf
+}
+
+// frequencyPacer adds to the length delay by how rare a word is, using an
+// embedded English frequency list: very common words go by faster and
+// words missing from the list stay up longer
+type frequencyPacer struct{}
+
+func (frequencyPacer) Delay(words []token, i int, wpm int) time.Duration {
+	word := words[i].Text
+	factor := 1 + 0.12*(typicalZipf-zipfFrequency(word))
+	factor = min(max(factor, 0.8), 1.5)
+	return time.Duration(wordTime(wpm) * lengthFactor(word) * factor)
+}
+
+// syllablePacer times words by their spoken length. English averages
+// about one and a half syllables a word, which gets the plain word time.
+type syllablePacer struct{}
+
+func (syllablePacer) Delay(words []token, i int, wpm int) time.Duration {
+	n := countSyllables(words[i].Text)
+	return time.Duration(wordTime(wpm) * (0.55 + 0.3*float64(n)))
+}
+
+// countSyllables estimates the syllables in word by counting groups of
+// vowels, not counting a silent final e. Words in scripts without Latin
+// vowels count one syllable per three letters, and each digit counts as one.
+func countSyllables(word string) int {
+	var base []rune
+	digits := 0
+	for _, r := range norm.NFD.String(strings.ToLower(word)) {
+		switch {
+		case unicode.IsLetter(r):
+			base = append(base, r)
+		case unicode.IsNumber(r):
+			digits++
+		}
+	}
+	if len(base) == 0 {
+		return max(digits, 1)
+	}
+
+	isVowel := func(r rune) bool { return strings.ContainsRune("aeiouy", r) }
+	count, vowels := 0, 0
+	for i, r := range base {
+		if !isVowel(r) {
+			continue
+		}
+		vowels++
+		if i == 0 || !isVowel(base[i-1]) {
+			count++
+		}
+	}
+	if vowels == 0 {
+		return (len(base)+2)/3 + digits
+	}
+	if n := len(base); n > 2 && base[n-1] == 'e' && !isVowel(base[n-2]) &&
+		!(base[n-2] == 'l' && !isVowel(base[n-3])) {
+		count--
+	}
+	return max(count, 1) + digits
+}
+
+// customPacer adds time to the length delay for words that are slow to
+// take in: numbers, proper nouns and long or hyphenated compounds
+type customPacer struct{}
+
+func (customPacer) Delay(words []token, i int, wpm int) time.Duration {
+	word := words[i].Text
+	factor := lengthFactor(word)
+	rs := letters(word)
+	if len(rs) == 0 {
+		return time.Duration(wordTime(wpm) * factor)
+	}
+
+	switch {
+	case slices.ContainsFunc(rs, unicode.IsNumber):
+		// Numbers are read digit by digit
+		factor *= 1.3 + 0.05*math.Max(float64(len(rs)-2), 0)
+	case unicode.IsUpper(rs[0]) && i > 0 && words[i-1].Sentence == words[i].Sentence:
+		// A capital inside a sentence marks a name or acronym
+		factor *= 1.2
+	}
+	if len(rs) >= 13 || strings.ContainsAny(strings.Trim(word, "-/"), "-/") {
+		factor *= 1.25
+	}
+	return time.Duration(wordTime(wpm) * factor)
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// At 60 WPM a word gets exactly one second, so delays read as factors
+const testWPM = 60
+
+func checkDelay(t *testing.T, p Pacer, words []token, i int, want float64) {
+	t.Helper()
+	got := p.Delay(words, i, testWPM)
+	if math.Abs(got.Seconds()-want) > 1e-6 {
+		t.Errorf("Delay(%q) = %v, want %v", words[i].Text, got, time.Duration(want*float64(time.Second)))
+	}
+}
+
+func TestLengthPacer(t *testing.T) {
+	tests := []struct {
+		word string
+		want float64
+	}{
+		{"cat", 1},
+		{"house", 1},
+		{"houses", 1.08},
+		{"elephants", 1.32},
+		{"extraordinary", 1.64},
+		{"Москва", 1.08},
+	}
+	for _, tt := range tests {
+		checkDelay(t, lengthPacer{}, []token{{Text: tt.word}}, 0, tt.want)
+	}
+}
+
+func TestFrequencyPacer(t *testing.T) {
+	tests := []struct {
+		word string
+		want float64
+	}{
+		{"the", 0.8},     // Very common words are clamped at 0.8
+		{"The", 0.8},     // Case doesn't matter
+		{"house", 0.916}, // 1 + 0.12*(5-5.7)
+		{"zebra", 1.18},  // Missing from the list, so rare
+		{"xylophonist", 1.48 * 1.18},
+	}
+	for _, tt := range tests {
+		checkDelay(t, frequencyPacer{}, []token{{Text: tt.word}}, 0, tt.want)
+	}
+}
+
+func TestSyllablePacer(t *testing.T) {
+	tests := []struct {
+		word string
+		want float64
+	}{
+		{"cat", 0.85},
+		{"reading", 1.15},
+		{"beautiful", 1.45},
+		{"1984", 1.75},
+	}
+	for _, tt := range tests {
+		checkDelay(t, syllablePacer{}, []token{{Text: tt.word}}, 0, tt.want)
+	}
+}
+
+func TestCustomPacer(t *testing.T) {
+	words := []token{
+		{Text: "Paris", Sentence: 0},
+		{Text: "met", Sentence: 0},
+		{Text: "Berlin", Sentence: 0},
+		{Text: "in", Sentence: 0},
+		{Text: "1984.", Sentence: 0},
+		{Text: "Well-known", Sentence: 1},
+		{Text: "well-known", Sentence: 1},
+		{Text: "extraordinarily", Sentence: 1},
+		{Text: "and/or", Sentence: 1},
+		{Text: "-", Sentence: 1},
+		{Text: "A4", Sentence: 1},
+	}
+	tests := []struct {
+		i    int
+		want float64
+	}{
+		{0, 1},           // A capital starting a sentence
+		{1, 1},           // A plain short word
+		{2, 1.08 * 1.2},  // A name inside the sentence
+		{4, 1.4},         // Four digits: 1.3 + 0.05*2
+		{5, 1.4 * 1.25},  // Starts a sentence, but hyphenated
+		{6, 1.4 * 1.25},  // Hyphenated
+		{7, 1.8 * 1.25},  // 13 letters or more
+		{8, 1.08 * 1.25}, // Slash compound
+		{9, 1},           // No letters at all
+		{10, 1.3},        // Numbers take precedence over capitals
+	}
+	for _, tt := range tests {
+		checkDelay(t, customPacer{}, words, tt.i, tt.want)
+	}
+}
+
+func TestCountSyllables(t *testing.T) {
+	tests := []struct {
+		word string
+		want int
+	}{
+		{"cat", 1},
+		{"make", 1},    // Silent e
+		{"table", 2},   // But not after a consonant and l
+		{"the", 1},     // At least one, even with a silent e
+		{"reading", 2}, // Vowel groups count once
+		{"beautiful", 3},
+		{"rhythm", 1}, // y is a vowel
+		{"Façade", 2}, // Accents are stripped before counting
+		{"Москва", 2}, // No Latin vowels: a syllable per three letters
+		{"1984", 4},   // A syllable per digit
+		{"A4", 2},
+		{"...", 1}, // Never less than one
+		{"", 1},
+	}
+	for _, tt := range tests {
+		if got := countSyllables(tt.word); got != tt.want {
+			t.Errorf("countSyllables(%q) = %d, want %d", tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestZipfFrequency(t *testing.T) {
+	tests := []struct {
+		word string
+		want float64
+	}{
+		{"the", 8.0},
+		{"The", 8.0},
+		{"house,", 5.7}, // Punctuation is ignored
+		{"“and”", 7.5},
+		{"zebra", rareZipf},
+		{"", rareZipf},
+	}
+	for _, tt := range tests {
+		if got := zipfFrequency(tt.word); got != tt.want {
+			t.Errorf("zipfFrequency(%q) = %v, want %v", tt.word, got, tt.want)
+		}
+	}
+}
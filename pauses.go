@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// pauseLength is an extra pause given either in milliseconds ("300",
+// "300ms") or as a multiple of the time one word gets ("1.5x"), which
+// scales with the reading speed
+type pauseLength struct {
+	Fixed time.Duration
+	Words float64
+}
+
+func (p *pauseLength) String() string {
+	if p.Words != 0 {
+		return strconv.FormatFloat(p.Words, 'g', -1, 64) + "x"
+	}
+	return strconv.FormatInt(p.Fixed.Milliseconds(), 10) + "ms"
+}
+
+func (p *pauseLength) Set(s string) error {
+	s = strings.TrimSpace(s)
+	var n float64
+	var err error
+	switch {
+	case strings.HasSuffix(s, "x"):
+		n, err = strconv.ParseFloat(strings.TrimSuffix(s, "x"), 64)
+		if err == nil && n >= 0 {
+			*p = pauseLength{Words: n}
+			return nil
+		}
+	default:
+		n, err = strconv.ParseFloat(strings.TrimSuffix(s, "ms"), 64)
+		if err == nil && n >= 0 {
+			*p = pauseLength{Fixed: time.Duration(n * float64(time.Millisecond))}
+			return nil
+		}
+	}
+	return fmt.Errorf("want milliseconds (300, 300ms) or a multiple of the word time (1.5x)")
+}
+
+// at resolves the pause for a word time of wordTime
+func (p pauseLength) at(wordTime time.Duration) time.Duration {
+	return p.Fixed + time.Duration(p.Words*float64(wordTime))
+}
+
+// pauseTable is the extra time given after a word for each kind of
+// boundary it ends
+type pauseTable struct {
+	Clause    pauseLength // Commas, semicolons, colons and dashes
+	Sentence  pauseLength
+	Paragraph pauseLength
+	Section   pauseLength // Chapters and headings
+	Dialogue  pauseLength // Closing quotes
+}
+
+// after returns the pause after words[i]: the longest pause of the
+// boundaries the word ends
+func (t *pauseTable) after(words []token, i int, wordTime time.Duration) time.Duration {
+	if words[i].Continued {
+		return 0 // The word goes on after the hyphen
+	}
+	word := words[i].Text
+	last := i == len(words)-1
+	var pause time.Duration
+	use := func(p pauseLength) {
+		pause = max(pause, p.at(wordTime))
+	}
+
+	if endsWithPunctuation(word) && !endsWithSentence(word) {
+		use(t.Clause)
+	}
+	if endsWithQuote(word) {
+		use(t.Dialogue)
+	}
+	if last || words[i+1].Sentence != words[i].Sentence {
+		use(t.Sentence)
+	}
+	if last || words[i+1].Paragraph != words[i].Paragraph {
+		use(t.Paragraph)
+	}
+	if !last && words[i+1].Section != words[i].Section {
+		use(t.Section)
+	}
+	return pause
+}
@@ -0,0 +1,848 @@
+package main
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"encoding/ascii85"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// PDF object types. Numbers are float64, strings are []byte and booleans
+// and null are bool and nil.
+type pdfName string
+
+type pdfRef struct {
+	Num, Gen int
+}
+
+type pdfDict map[pdfName]any
+
+type pdfArray []any
+
+type pdfStream struct {
+	Dict pdfDict
+	Raw  []byte // Still encoded with the stream's /Filter
+}
+
+// pdfKeyword is a bare word in a content stream or CMap (an operator)
+type pdfKeyword string
+
+type xrefEntry struct {
+	Offset   int
+	InStream bool // Object is compressed inside an object stream
+	Stream   int  // Object stream number (InStream only)
+	Index    int  // Index within the object stream (InStream only)
+}
+
+// pdfFile gives access to the objects of a PDF held in memory
+type pdfFile struct {
+	data    []byte
+	xref    map[int]xrefEntry
+	trailer pdfDict
+	cache   map[int]any
+	loading map[int]bool // Guards against reference cycles
+}
+
+func isPDF(data []byte) bool {
+	head := data
+	if len(head) > 1024 {
+		head = head[:1024]
+	}
+	return bytes.Contains(head, []byte("%PDF-"))
+}
+
+func openPDF(data []byte) (*pdfFile, error) {
+	f := &pdfFile{
+		data:    data,
+		xref:    make(map[int]xrefEntry),
+		cache:   make(map[int]any),
+		loading: make(map[int]bool),
+	}
+	if err := f.readXref(); err != nil || f.trailer[pdfName("Root")] == nil {
+		// Damaged or missing cross-reference data: rebuild it by scanning
+		f.xref = make(map[int]xrefEntry)
+		f.trailer = nil
+		if err := f.reconstructXref(); err != nil {
+			return nil, err
+		}
+	}
+	if f.trailer[pdfName("Encrypt")] != nil {
+		return nil, errors.New("encrypted PDFs are not supported")
+	}
+	return f, nil
+}
+
+// readXref follows the startxref pointer and the /Prev chain, reading both
+// classic cross-reference tables and cross-reference streams.
+func (f *pdfFile) readXref() error {
+	idx := bytes.LastIndex(f.data, []byte("startxref"))
+	if idx < 0 {
+		return errors.New("missing startxref")
+	}
+	lx := &pdfLexer{data: f.data, pos: idx + len("startxref")}
+	off, ok := lx.next().(float64)
+	if !ok {
+		return errors.New("invalid startxref")
+	}
+
+	seen := make(map[int]bool)
+	offset := int(off)
+	for offset > 0 && offset < len(f.data) && !seen[offset] {
+		seen[offset] = true
+		var trailer pdfDict
+		var err error
+		if bytes.HasPrefix(f.data[offset:], []byte("xref")) {
+			trailer, err = f.readXrefTable(offset)
+		} else {
+			trailer, err = f.readXrefStream(offset)
+		}
+		if err != nil {
+			return err
+		}
+		if f.trailer == nil {
+			f.trailer = trailer
+		}
+		// Hybrid files keep their compressed objects in a separate stream
+		if stm, ok := trailer[pdfName("XRefStm")].(float64); ok {
+			f.readXrefStream(int(stm))
+		}
+		prev, ok := trailer[pdfName("Prev")].(float64)
+		if !ok {
+			break
+		}
+		offset = int(prev)
+	}
+	if f.trailer == nil {
+		return errors.New("missing trailer")
+	}
+	return nil
+}
+
+func (f *pdfFile) readXrefTable(offset int) (pdfDict, error) {
+	lx := &pdfLexer{data: f.data, pos: offset + len("xref")}
+	for {
+		tok := lx.next()
+		if kw, ok := tok.(pdfKeyword); ok && kw == "trailer" {
+			break
+		}
+		start, ok1 := tok.(float64)
+		count, ok2 := lx.next().(float64)
+		if !ok1 || !ok2 {
+			return nil, errors.New("invalid xref table")
+		}
+		for i := 0; i < int(count); i++ {
+			entryOff, ok1 := lx.next().(float64)
+			lx.next() // generation
+			kind, ok2 := lx.next().(pdfKeyword)
+			if !ok1 || !ok2 {
+				return nil, errors.New("invalid xref entry")
+			}
+			num := int(start) + i
+			if _, exists := f.xref[num]; exists || kind != "n" {
+				continue
+			}
+			f.xref[num] = xrefEntry{Offset: int(entryOff)}
+		}
+	}
+	trailer, ok := lx.next().(pdfDict)
+	if !ok {
+		return nil, errors.New("invalid trailer")
+	}
+	return trailer, nil
+}
+
+func (f *pdfFile) readXrefStream(offset int) (pdfDict, error) {
+	obj, err := f.parseIndirect(offset)
+	if err != nil {
+		return nil, err
+	}
+	stm, ok := obj.(pdfStream)
+	if !ok {
+		return nil, errors.New("invalid xref stream")
+	}
+	data, err := f.decodeStream(stm)
+	if err != nil {
+		return nil, err
+	}
+
+	w, _ := stm.Dict[pdfName("W")].(pdfArray)
+	if len(w) != 3 {
+		return nil, errors.New("invalid xref stream widths")
+	}
+	widths := make([]int, 3)
+	for i := range widths {
+		widths[i] = f.int(w[i])
+		if widths[i] < 0 || widths[i] > 8 {
+			return nil, errors.New("invalid xref stream widths")
+		}
+	}
+	entrySize := widths[0] + widths[1] + widths[2]
+	if entrySize == 0 || entrySize > len(data) {
+		return nil, errors.New("invalid xref stream widths")
+	}
+	index, _ := stm.Dict[pdfName("Index")].(pdfArray)
+	if index == nil {
+		index = pdfArray{0.0, stm.Dict[pdfName("Size")]}
+	}
+
+	field := func(b []byte) int {
+		v := 0
+		for _, c := range b {
+			v = v<<8 | int(c)
+		}
+		return v
+	}
+	pos := 0
+	for i := 0; i+1 < len(index); i += 2 {
+		start, count := f.int(index[i]), f.int(index[i+1])
+		for j := 0; j < count && pos+entrySize <= len(data); j++ {
+			entry := data[pos : pos+entrySize]
+			pos += entrySize
+			kind := 1 // Type defaults to 1 when its field is absent
+			if widths[0] > 0 {
+				kind = field(entry[:widths[0]])
+			}
+			a := field(entry[widths[0] : widths[0]+widths[1]])
+			b := field(entry[widths[0]+widths[1]:])
+			num := start + j
+			if _, exists := f.xref[num]; exists {
+				continue
+			}
+			switch kind {
+			case 1:
+				f.xref[num] = xrefEntry{Offset: a}
+			case 2:
+				f.xref[num] = xrefEntry{InStream: true, Stream: a, Index: b}
+			}
+		}
+	}
+	return stm.Dict, nil
+}
+
+var objHeader = regexp.MustCompile(`(?m)(\d+)\s+(\d+)\s+obj\b`)
+
+// reconstructXref rebuilds the cross-reference table of a damaged file by
+// scanning for "N G obj" headers.
+func (f *pdfFile) reconstructXref() error {
+	for _, m := range objHeader.FindAllSubmatchIndex(f.data, -1) {
+		num, _ := strconv.Atoi(string(f.data[m[2]:m[3]]))
+		f.xref[num] = xrefEntry{Offset: m[0]} // Later definitions win
+	}
+	if len(f.xref) == 0 {
+		return errors.New("no objects found")
+	}
+
+	// Index the objects packed inside object streams
+	nums := make([]int, 0, len(f.xref))
+	for num := range f.xref {
+		nums = append(nums, num)
+	}
+	for _, num := range nums {
+		stm, ok := f.object(num).(pdfStream)
+		if !ok || stm.Dict[pdfName("Type")] != pdfName("ObjStm") {
+			continue
+		}
+		data, err := f.decodeStream(stm)
+		if err != nil {
+			continue
+		}
+		lx := &pdfLexer{data: data}
+		for i := 0; i < f.int(stm.Dict[pdfName("N")]); i++ {
+			inner, ok := lx.next().(float64)
+			lx.next() // offset
+			if !ok {
+				break
+			}
+			if _, exists := f.xref[int(inner)]; !exists {
+				f.xref[int(inner)] = xrefEntry{InStream: true, Stream: num, Index: i}
+			}
+		}
+	}
+
+	// Use the last trailer dictionary, or any xref stream that names a catalog
+	if idx := bytes.LastIndex(f.data, []byte("trailer")); idx >= 0 {
+		lx := &pdfLexer{data: f.data, pos: idx + len("trailer")}
+		if trailer, ok := lx.next().(pdfDict); ok && trailer[pdfName("Root")] != nil {
+			f.trailer = trailer
+			return nil
+		}
+	}
+	for num := range f.xref {
+		if stm, ok := f.object(num).(pdfStream); ok && stm.Dict[pdfName("Root")] != nil {
+			f.trailer = stm.Dict
+			return nil
+		}
+	}
+	for num := range f.xref {
+		if d, ok := f.object(num).(pdfDict); ok && d[pdfName("Type")] == pdfName("Catalog") {
+			f.trailer = pdfDict{pdfName("Root"): pdfRef{Num: num}}
+			return nil
+		}
+	}
+	return errors.New("no document catalog found")
+}
+
+// parseIndirect parses the "N G obj ... endobj" object starting at offset
+func (f *pdfFile) parseIndirect(offset int) (any, error) {
+	if offset < 0 || offset >= len(f.data) {
+		return nil, errors.New("object offset out of range")
+	}
+	lx := &pdfLexer{data: f.data, pos: offset}
+	lx.next() // object number
+	lx.next() // generation
+	if kw, ok := lx.next().(pdfKeyword); !ok || kw != "obj" {
+		return nil, fmt.Errorf("no object at offset %d", offset)
+	}
+	obj := lx.next()
+	dict, ok := obj.(pdfDict)
+	if !ok {
+		return obj, nil
+	}
+
+	// A dictionary followed by "stream" is a stream object
+	save := lx.pos
+	if kw, ok := lx.next().(pdfKeyword); !ok || kw != "stream" {
+		lx.pos = save
+		return dict, nil
+	}
+	start := lx.pos
+	if start < len(f.data) && f.data[start] == '\r' {
+		start++
+	}
+	if start < len(f.data) && f.data[start] == '\n' {
+		start++
+	}
+	length := -1
+	if l, ok := f.resolve(dict[pdfName("Length")]).(float64); ok {
+		length = int(l)
+	}
+	end := start + length
+	if length < 0 || end > len(f.data) || !bytes.HasPrefix(bytes.TrimLeft(f.data[end:], " \r\n"), []byte("endstream")) {
+		// Missing or wrong /Length: look for the end marker instead
+		idx := bytes.Index(f.data[start:], []byte("endstream"))
+		if idx < 0 {
+			return nil, errors.New("unterminated stream")
+		}
+		end = start + idx
+		for end > start && (f.data[end-1] == '\n' || f.data[end-1] == '\r') {
+			end--
+		}
+	}
+	return pdfStream{Dict: dict, Raw: f.data[start:end]}, nil
+}
+
+// object loads indirect object num, returning nil if it cannot be read
+func (f *pdfFile) object(num int) any {
+	if obj, ok := f.cache[num]; ok {
+		return obj
+	}
+	if f.loading[num] {
+		return nil
+	}
+	entry, ok := f.xref[num]
+	if !ok {
+		return nil
+	}
+	f.loading[num] = true
+	defer delete(f.loading, num)
+
+	var obj any
+	if entry.InStream {
+		obj = f.compressedObject(entry.Stream, entry.Index)
+	} else {
+		obj, _ = f.parseIndirect(entry.Offset)
+	}
+	f.cache[num] = obj
+	return obj
+}
+
+// compressedObject reads the index'th object out of object stream num
+func (f *pdfFile) compressedObject(num, index int) any {
+	stm, ok := f.object(num).(pdfStream)
+	if !ok {
+		return nil
+	}
+	data, err := f.decodeStream(stm)
+	if err != nil {
+		return nil
+	}
+	n := f.int(stm.Dict[pdfName("N")])
+	first := f.int(stm.Dict[pdfName("First")])
+	if index < 0 || index >= n || first < 0 || first > len(data) {
+		return nil
+	}
+
+	lx := &pdfLexer{data: data}
+	var offset int
+	for i := 0; i <= index; i++ {
+		lx.next() // object number
+		off, ok := lx.next().(float64)
+		if !ok {
+			return nil
+		}
+		offset = int(off)
+	}
+	if first < 0 || offset < 0 || first+offset >= len(data) {
+		return nil
+	}
+	lx = &pdfLexer{data: data, pos: first + offset}
+	return lx.next()
+}
+
+// resolve follows indirect references until it reaches a direct object
+func (f *pdfFile) resolve(obj any) any {
+	for i := 0; i < 32; i++ {
+		ref, ok := obj.(pdfRef)
+		if !ok {
+			return obj
+		}
+		obj = f.object(ref.Num)
+	}
+	return nil
+}
+
+func (f *pdfFile) dict(obj any) pdfDict {
+	switch v := f.resolve(obj).(type) {
+	case pdfDict:
+		return v
+	case pdfStream:
+		return v.Dict
+	}
+	return nil
+}
+
+func (f *pdfFile) array(obj any) pdfArray {
+	a, _ := f.resolve(obj).(pdfArray)
+	return a
+}
+
+func (f *pdfFile) int(obj any) int {
+	n, _ := f.resolve(obj).(float64)
+	return int(n)
+}
+
+func (f *pdfFile) number(obj any) (float64, bool) {
+	n, ok := f.resolve(obj).(float64)
+	return n, ok
+}
+
+// decodeStream applies a stream's filters to return its decoded contents
+func (f *pdfFile) decodeStream(stm pdfStream) ([]byte, error) {
+	data := stm.Raw
+	var filters, params pdfArray
+	switch v := f.resolve(stm.Dict[pdfName("Filter")]).(type) {
+	case pdfName:
+		filters = pdfArray{v}
+	case pdfArray:
+		filters = v
+	}
+	switch v := f.resolve(stm.Dict[pdfName("DecodeParms")]).(type) {
+	case pdfDict:
+		params = pdfArray{v}
+	case pdfArray:
+		params = v
+	}
+
+	for i, filter := range filters {
+		var parms pdfDict
+		if i < len(params) {
+			parms = f.dict(params[i])
+		}
+		var err error
+		switch f.resolve(filter) {
+		case pdfName("FlateDecode"), pdfName("Fl"):
+			data, err = inflate(data)
+			if err == nil {
+				data, err = f.unpredict(data, parms)
+			}
+		case pdfName("ASCIIHexDecode"), pdfName("AHx"):
+			data, err = decodeASCIIHex(data)
+		case pdfName("ASCII85Decode"), pdfName("A85"):
+			data, err = decodeASCII85(data)
+		default:
+			return nil, fmt.Errorf("unsupported filter %v", filter)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// inflate decompresses zlib data, tolerating truncated streams and
+// streams written without the zlib header
+func inflate(data []byte) ([]byte, error) {
+	var r io.ReadCloser
+	r, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		r = flate.NewReader(bytes.NewReader(data))
+	}
+	defer r.Close()
+	out, err := io.ReadAll(r)
+	if err != nil && len(out) == 0 {
+		return nil, err
+	}
+	return out, nil
+}
+
+// unpredict reverses the PNG or TIFF predictor applied before compression
+func (f *pdfFile) unpredict(data []byte, parms pdfDict) ([]byte, error) {
+	predictor := f.int(parms[pdfName("Predictor")])
+	if predictor <= 1 {
+		return data, nil
+	}
+	columns := 1
+	if c := f.int(parms[pdfName("Columns")]); c > 0 {
+		columns = c
+	}
+	colors := 1
+	if c := f.int(parms[pdfName("Colors")]); c > 0 {
+		colors = c
+	}
+	bpc := 8
+	if b := f.int(parms[pdfName("BitsPerComponent")]); b > 0 {
+		bpc = b
+	}
+	bpp := (colors*bpc + 7) / 8
+	rowLen := (columns*colors*bpc + 7) / 8
+
+	if predictor == 2 {
+		// TIFF predictor 2 (8-bit components only)
+		out := append([]byte(nil), data...)
+		for row := 0; row+rowLen <= len(out); row += rowLen {
+			for i := bpp; i < rowLen; i++ {
+				out[row+i] += out[row+i-bpp]
+			}
+		}
+		return out, nil
+	}
+
+	// PNG predictors: every row starts with a filter type byte
+	var out []byte
+	prev := make([]byte, rowLen)
+	for pos := 0; pos+1+rowLen <= len(data); pos += 1 + rowLen {
+		filter := data[pos]
+		row := append([]byte(nil), data[pos+1:pos+1+rowLen]...)
+		for i := range row {
+			var left, upLeft byte
+			if i >= bpp {
+				left = row[i-bpp]
+				upLeft = prev[i-bpp]
+			}
+			up := prev[i]
+			switch filter {
+			case 1:
+				row[i] += left
+			case 2:
+				row[i] += up
+			case 3:
+				row[i] += byte((int(left) + int(up)) / 2)
+			case 4:
+				row[i] += paeth(left, up, upLeft)
+			}
+		}
+		out = append(out, row...)
+		prev = row
+	}
+	return out, nil
+}
+
+func paeth(a, b, c byte) byte {
+	p := int(a) + int(b) - int(c)
+	pa, pb, pc := abs(p-int(a)), abs(p-int(b)), abs(p-int(c))
+	switch {
+	case pa <= pb && pa <= pc:
+		return a
+	case pb <= pc:
+		return b
+	default:
+		return c
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func decodeASCIIHex(data []byte) ([]byte, error) {
+	var digits []byte
+	for _, c := range data {
+		if c == '>' {
+			break
+		}
+		if isHexDigit(c) {
+			digits = append(digits, c)
+		}
+	}
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	return hex.DecodeString(string(digits))
+}
+
+func decodeASCII85(data []byte) ([]byte, error) {
+	data = bytes.TrimSpace(data)
+	data = bytes.TrimPrefix(data, []byte("<~"))
+	if idx := bytes.Index(data, []byte("~>")); idx >= 0 {
+		data = data[:idx]
+	}
+	out := make([]byte, 4*len(data)/5+4)
+	n, _, err := ascii85.Decode(out, data, true)
+	return out[:n], err
+}
+
+func isHexDigit(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func isPDFWhitespace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == 0
+}
+
+func isPDFDelimiter(c byte) bool {
+	return strings.IndexByte("()<>[]{}/%", c) >= 0
+}
+
+// pdfLexer reads PDF objects and keywords from a byte slice. It is used
+// for the file structure as well as content streams and CMaps.
+type pdfLexer struct {
+	data []byte
+	pos  int
+}
+
+func (lx *pdfLexer) skipSpace() {
+	for lx.pos < len(lx.data) {
+		c := lx.data[lx.pos]
+		if c == '%' {
+			for lx.pos < len(lx.data) && lx.data[lx.pos] != '\n' && lx.data[lx.pos] != '\r' {
+				lx.pos++
+			}
+			continue
+		}
+		if !isPDFWhitespace(c) {
+			return
+		}
+		lx.pos++
+	}
+}
+
+func (lx *pdfLexer) eof() bool {
+	lx.skipSpace()
+	return lx.pos >= len(lx.data)
+}
+
+// next returns the next object, resolving "N G R" into a pdfRef. Bare
+// words are returned as pdfKeyword; nil is returned at end of input.
+func (lx *pdfLexer) next() any {
+	tok := lx.token()
+	n, ok := tok.(float64)
+	if !ok || n != float64(int(n)) {
+		return tok
+	}
+	// Look ahead for an indirect reference
+	save := lx.pos
+	if gen, ok := lx.token().(float64); ok {
+		if kw, ok := lx.token().(pdfKeyword); ok && kw == "R" {
+			return pdfRef{Num: int(n), Gen: int(gen)}
+		}
+	}
+	lx.pos = save
+	return n
+}
+
+func (lx *pdfLexer) token() any {
+	lx.skipSpace()
+	if lx.pos >= len(lx.data) {
+		return nil
+	}
+	c := lx.data[lx.pos]
+	switch {
+	case c == '/':
+		return lx.name()
+	case c == '(':
+		return lx.literalString()
+	case c == '<' && lx.pos+1 < len(lx.data) && lx.data[lx.pos+1] == '<':
+		lx.pos += 2
+		return lx.dictionary()
+	case c == '<':
+		return lx.hexString()
+	case c == '[':
+		lx.pos++
+		var arr pdfArray
+		for !lx.eof() && lx.data[lx.pos] != ']' {
+			arr = append(arr, lx.next())
+		}
+		lx.pos++
+		return arr
+	case c == ']' || c == '>' || c == ')' || c == '{' || c == '}':
+		lx.pos++
+		return pdfKeyword(c)
+	case c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9'):
+		return lx.number()
+	}
+	start := lx.pos
+	for lx.pos < len(lx.data) && !isPDFWhitespace(lx.data[lx.pos]) && !isPDFDelimiter(lx.data[lx.pos]) {
+		lx.pos++
+	}
+	word := string(lx.data[start:lx.pos])
+	switch word {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "null":
+		return nil
+	}
+	return pdfKeyword(word)
+}
+
+func (lx *pdfLexer) dictionary() pdfDict {
+	dict := make(pdfDict)
+	for !lx.eof() {
+		if bytes.HasPrefix(lx.data[lx.pos:], []byte(">>")) {
+			lx.pos += 2
+			break
+		}
+		key, ok := lx.token().(pdfName)
+		if !ok {
+			continue
+		}
+		dict[key] = lx.next()
+	}
+	return dict
+}
+
+func (lx *pdfLexer) name() pdfName {
+	lx.pos++ // '/'
+	var sb strings.Builder
+	for lx.pos < len(lx.data) {
+		c := lx.data[lx.pos]
+		if isPDFWhitespace(c) || isPDFDelimiter(c) {
+			break
+		}
+		if c == '#' && lx.pos+2 < len(lx.data) && isHexDigit(lx.data[lx.pos+1]) && isHexDigit(lx.data[lx.pos+2]) {
+			b, _ := hex.DecodeString(string(lx.data[lx.pos+1 : lx.pos+3]))
+			sb.Write(b)
+			lx.pos += 3
+			continue
+		}
+		sb.WriteByte(c)
+		lx.pos++
+	}
+	return pdfName(sb.String())
+}
+
+func (lx *pdfLexer) number() any {
+	start := lx.pos
+	lx.pos++
+	for lx.pos < len(lx.data) {
+		c := lx.data[lx.pos]
+		if (c < '0' || c > '9') && c != '.' {
+			break
+		}
+		lx.pos++
+	}
+	n, err := strconv.ParseFloat(string(lx.data[start:lx.pos]), 64)
+	if err != nil {
+		return pdfKeyword(lx.data[start:lx.pos])
+	}
+	return n
+}
+
+func (lx *pdfLexer) hexString() []byte {
+	lx.pos++ // '<'
+	end := bytes.IndexByte(lx.data[lx.pos:], '>')
+	if end < 0 {
+		end = len(lx.data) - lx.pos
+	}
+	s, _ := decodeASCIIHex(lx.data[lx.pos : lx.pos+end])
+	lx.pos += end + 1
+	return s
+}
+
+func (lx *pdfLexer) literalString() []byte {
+	lx.pos++ // '('
+	var out []byte
+	depth := 1
+	for lx.pos < len(lx.data) {
+		c := lx.data[lx.pos]
+		lx.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return out
+			}
+		case '\\':
+			if lx.pos >= len(lx.data) {
+				return out
+			}
+			c = lx.data[lx.pos]
+			lx.pos++
+			switch c {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				if lx.pos < len(lx.data) && lx.data[lx.pos] == '\n' {
+					lx.pos++
+				}
+				continue
+			case '\n':
+				continue
+			default:
+				if c >= '0' && c <= '7' {
+					v := int(c - '0')
+					for i := 0; i < 2 && lx.pos < len(lx.data) && lx.data[lx.pos] >= '0' && lx.data[lx.pos] <= '7'; i++ {
+						v = v*8 + int(lx.data[lx.pos]-'0')
+						lx.pos++
+					}
+					c = byte(v)
+				}
+			}
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// skipInlineImage moves past the binary data of an inline image (BI ... ID data EI)
+func (lx *pdfLexer) skipInlineImage() {
+	for {
+		tok := lx.token()
+		if tok == nil {
+			return
+		}
+		if kw, ok := tok.(pdfKeyword); ok && kw == "ID" {
+			break
+		}
+	}
+	for lx.pos+2 < len(lx.data) {
+		if isPDFWhitespace(lx.data[lx.pos]) && lx.data[lx.pos+1] == 'E' && lx.data[lx.pos+2] == 'I' &&
+			(lx.pos+3 >= len(lx.data) || isPDFWhitespace(lx.data[lx.pos+3])) {
+			lx.pos += 3
+			return
+		}
+		lx.pos++
+	}
+	lx.pos = len(lx.data)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// buildPDF lays out objects numbered from 1 and ends the file with tail,
+// which is given the offset of the object numbered xrefObj (0 for none)
+func buildPDF(objects []string, xrefObj int, tail string) []byte {
+	var sb strings.Builder
+	sb.WriteString("%PDF-1.5\n")
+	offsets := make([]int, len(objects)+1)
+	for i, obj := range objects {
+		offsets[i+1] = sb.Len()
+		fmt.Fprintf(&sb, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	if xrefObj > 0 {
+		tail = fmt.Sprintf(tail, offsets[xrefObj])
+	}
+	sb.WriteString(tail)
+	return []byte(sb.String())
+}
+
+func pdfStreamObject(dict, data string) string {
+	return fmt.Sprintf("<< %s /Length %d >>\nstream\n%s\nendstream", dict, len(data), data)
+}
+
+var testPDFObjects = []string{
+	"<< /Type /Catalog /Pages 2 0 R >>",
+	"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+	"<< /Type /Page /Parent 2 0 R /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> >>",
+	pdfStreamObject("", "BT /F1 12 Tf 72 700 Td (Hello from page one) Tj ET"),
+	"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+}
+
+func TestReadPDFBadXrefStreamWidths(t *testing.T) {
+	for _, w := range []string{"[1 -2 3]", "[9 2 1]", "[0 0 0]", "[1 200 1]"} {
+		objects := append(testPDFObjects[:len(testPDFObjects):len(testPDFObjects)],
+			pdfStreamObject("/Type /XRef /Size 7 /Root 1 0 R /W "+w, "\x01\x00\x0a\x00"))
+		data := buildPDF(objects, 6, "startxref\n%d\n%%%%EOF\n")
+
+		src, err := readPDF(data)
+		if err != nil {
+			t.Errorf("/W %s: %v", w, err)
+			continue
+		}
+		if !strings.Contains(src.Text, "Hello from page one") {
+			t.Errorf("/W %s: text %q, want it read after rebuilding the xref", w, src.Text)
+		}
+	}
+}
+
+func TestCompressedObjectOutOfRange(t *testing.T) {
+	tests := []struct {
+		name string
+		dict string
+		data string
+	}{
+		{"negative /First", "/Type /ObjStm /N 1 /First -50", "7 0 (x)"},
+		{"negative offset", "/Type /ObjStm /N 1 /First 8", "7 -60 (x)"},
+		{"offset past the end", "/Type /ObjStm /N 1 /First 8", "7 40 (x)"},
+		{"/First past the end", "/Type /ObjStm /N 1 /First 99", "7 0 (x)"},
+	}
+	for _, tt := range tests {
+		data := buildPDF([]string{pdfStreamObject(tt.dict, tt.data)}, 0, "")
+		f := &pdfFile{
+			data: data,
+			xref: map[int]xrefEntry{
+				1: {Offset: strings.Index(string(data), "1 0 obj")},
+				7: {InStream: true, Stream: 1, Index: 0},
+			},
+			cache:   make(map[int]any),
+			loading: make(map[int]bool),
+		}
+		if obj := f.object(7); obj != nil {
+			t.Errorf("%s: got %v, want nil", tt.name, obj)
+		}
+	}
+}
+
+func TestCompressedObject(t *testing.T) {
+	data := buildPDF([]string{pdfStreamObject("/Type /ObjStm /N 2 /First 9", "7 0 8 4 \n(a) (b)")}, 0, "")
+	f := &pdfFile{
+		data: data,
+		xref: map[int]xrefEntry{
+			1: {Offset: strings.Index(string(data), "1 0 obj")},
+			8: {InStream: true, Stream: 1, Index: 1},
+		},
+		cache:   make(map[int]any),
+		loading: make(map[int]bool),
+	}
+	if obj, ok := f.object(8).([]byte); !ok || string(obj) != "b" {
+		t.Errorf("got %#v, want \"b\"", f.object(8))
+	}
+}
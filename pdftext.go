@@ -0,0 +1,930 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// readPDF extracts the text of every page in order, recording where each
+// page starts in the resulting text.
+func readPDF(data []byte) (source, error) {
+	f, err := openPDF(data)
+	if err != nil {
+		return source{}, fmt.Errorf("invalid PDF: %w", err)
+	}
+	root := f.dict(f.trailer[pdfName("Root")])
+	if root == nil {
+		return source{}, errors.New("invalid PDF: missing document catalog")
+	}
+
+	var pages []pdfDict
+	f.collectPages(root[pdfName("Pages")], nil, &pages, make(map[pdfRef]bool))
+	if len(pages) == 0 {
+		return source{}, errors.New("invalid PDF: no pages")
+	}
+
+	var src source
+	var text strings.Builder
+	fonts := make(map[pdfRef]*pdfFont)
+	pageIndex := make(map[pdfRef]int)
+	for i, page := range pages {
+		if ref, ok := page[pdfName("_ref")].(pdfRef); ok {
+			pageIndex[ref] = i
+		}
+	}
+	for _, page := range pages {
+		if text.Len() > 0 {
+			text.WriteString("\n\n")
+		}
+		src.Pages = append(src.Pages, text.Len())
+
+		ex := &pdfExtractor{file: f, fonts: fonts}
+		content := f.pageContent(page)
+		ex.run(content, f.dict(page[pdfName("Resources")]), identityMatrix, 0)
+		text.WriteString(strings.TrimSpace(ex.out.String()))
+	}
+
+	src.Text = text.String()
+	if strings.TrimSpace(src.Text) == "" {
+		return source{}, errors.New("PDF contains no extractable text (scanned images?)")
+	}
+
+	// Document outline entries become sections starting at their target page
+	for _, o := range f.outline(root, pageIndex) {
+		src.Sections = append(src.Sections, section{Title: o.Title, Offset: src.Pages[o.Page], Level: o.Level})
+	}
+	sortSections(src.Sections)
+	return src, nil
+}
+
+type outlineEntry struct {
+	Title string
+	Page  int
+	Level int
+}
+
+// outline flattens the document outline (bookmarks) into entries that point at known pages
+func (f *pdfFile) outline(root pdfDict, pageIndex map[pdfRef]int) []outlineEntry {
+	var entries []outlineEntry
+	seen := make(map[pdfRef]bool)
+	var walk func(item any, level int)
+	walk = func(item any, level int) {
+		for item != nil {
+			ref, ok := item.(pdfRef)
+			if !ok || seen[ref] {
+				return
+			}
+			seen[ref] = true
+			node := f.dict(ref)
+			if node == nil {
+				return
+			}
+
+			dest := node[pdfName("Dest")]
+			if action := f.dict(node[pdfName("A")]); action != nil && action[pdfName("S")] == pdfName("GoTo") {
+				dest = action[pdfName("D")]
+			}
+			if page, ok := f.destPage(root, dest, pageIndex); ok {
+				title, _ := f.resolve(node[pdfName("Title")]).([]byte)
+				entries = append(entries, outlineEntry{Title: pdfTextString(title), Page: page, Level: level})
+			}
+
+			walk(node[pdfName("First")], level+1)
+			item = node[pdfName("Next")]
+		}
+	}
+	if outlines := f.dict(root[pdfName("Outlines")]); outlines != nil {
+		walk(outlines[pdfName("First")], 0)
+	}
+	return entries
+}
+
+// destPage resolves an explicit or named destination to a page index
+func (f *pdfFile) destPage(root pdfDict, dest any, pageIndex map[pdfRef]int) (int, bool) {
+	switch d := f.resolve(dest).(type) {
+	case []byte:
+		dest = f.lookupName(f.dict(f.dict(root[pdfName("Names")])[pdfName("Dests")]), string(d))
+	case pdfName:
+		dest = f.dict(root[pdfName("Dests")])[d]
+	}
+	if dict := f.dict(dest); dict != nil {
+		dest = dict[pdfName("D")]
+	}
+	arr := f.array(dest)
+	if len(arr) == 0 {
+		return 0, false
+	}
+	ref, ok := arr[0].(pdfRef)
+	if !ok {
+		return 0, false
+	}
+	page, ok := pageIndex[ref]
+	return page, ok
+}
+
+// lookupName finds key in a name tree
+func (f *pdfFile) lookupName(node pdfDict, key string) any {
+	for depth := 0; node != nil && depth < 32; depth++ {
+		names := f.array(node[pdfName("Names")])
+		for i := 0; i+1 < len(names); i += 2 {
+			if k, ok := f.resolve(names[i]).([]byte); ok && string(k) == key {
+				return names[i+1]
+			}
+		}
+		var next pdfDict
+		for _, kid := range f.array(node[pdfName("Kids")]) {
+			kd := f.dict(kid)
+			limits := f.array(kd[pdfName("Limits")])
+			if len(limits) == 2 {
+				lo, _ := f.resolve(limits[0]).([]byte)
+				hi, _ := f.resolve(limits[1]).([]byte)
+				if key < string(lo) || key > string(hi) {
+					continue
+				}
+			}
+			next = kd
+			break
+		}
+		node = next
+	}
+	return nil
+}
+
+// pdfTextString decodes a PDF text string (UTF-16BE with BOM, or PDFDocEncoding)
+func pdfTextString(b []byte) string {
+	if len(b) >= 2 && b[0] == 0xfe && b[1] == 0xff {
+		return strings.TrimSpace(utf16BEString(b[2:]))
+	}
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = winAnsiEncoding[c]
+		if runes[i] == 0 {
+			runes[i] = rune(c)
+		}
+	}
+	return strings.TrimSpace(string(runes))
+}
+
+// collectPages walks the page tree in order, copying inherited
+// attributes down to each page
+func (f *pdfFile) collectPages(obj any, inherited pdfDict, pages *[]pdfDict, seen map[pdfRef]bool) {
+	if ref, ok := obj.(pdfRef); ok {
+		if seen[ref] {
+			return
+		}
+		seen[ref] = true
+	}
+	node := f.dict(obj)
+	if node == nil {
+		return
+	}
+
+	attrs := make(pdfDict)
+	for k, v := range inherited {
+		attrs[k] = v
+	}
+	if res, ok := node[pdfName("Resources")]; ok {
+		attrs[pdfName("Resources")] = res
+	}
+
+	kids := f.array(node[pdfName("Kids")])
+	if node[pdfName("Type")] == pdfName("Page") || kids == nil {
+		page := make(pdfDict)
+		for k, v := range attrs {
+			page[k] = v
+		}
+		for k, v := range node {
+			page[k] = v
+		}
+		// Remember the page's reference so outline destinations can find it
+		if ref, ok := obj.(pdfRef); ok {
+			page[pdfName("_ref")] = ref
+		}
+		*pages = append(*pages, page)
+		return
+	}
+	for _, kid := range kids {
+		f.collectPages(kid, attrs, pages, seen)
+	}
+}
+
+// pageContent returns the decoded, concatenated content streams of a page
+func (f *pdfFile) pageContent(page pdfDict) []byte {
+	var streams []any
+	switch v := f.resolve(page[pdfName("Contents")]).(type) {
+	case pdfStream:
+		streams = []any{v}
+	case pdfArray:
+		streams = v
+	}
+	var out []byte
+	for _, s := range streams {
+		stm, ok := f.resolve(s).(pdfStream)
+		if !ok {
+			continue
+		}
+		data, err := f.decodeStream(stm)
+		if err != nil {
+			continue
+		}
+		out = append(out, data...)
+		out = append(out, '\n')
+	}
+	return out
+}
+
+type matrix [6]float64
+
+var identityMatrix = matrix{1, 0, 0, 1, 0, 0}
+
+// mul returns m × n
+func (m matrix) mul(n matrix) matrix {
+	return matrix{
+		m[0]*n[0] + m[1]*n[2],
+		m[0]*n[1] + m[1]*n[3],
+		m[2]*n[0] + m[3]*n[2],
+		m[2]*n[1] + m[3]*n[3],
+		m[4]*n[0] + m[5]*n[2] + n[4],
+		m[4]*n[1] + m[5]*n[3] + n[5],
+	}
+}
+
+func translate(tx, ty float64) matrix {
+	return matrix{1, 0, 0, 1, tx, ty}
+}
+
+type pdfGraphicsState struct {
+	ctm matrix
+}
+
+// pdfExtractor interprets content stream operators that affect text,
+// turning glyph positions into words, lines and paragraphs.
+type pdfExtractor struct {
+	file  *pdfFile
+	fonts map[pdfRef]*pdfFont
+	out   strings.Builder
+
+	// Position of the end of the last glyph in device space
+	lastX, lastY float64
+	started      bool
+	pendingBreak int // 0 none, 1 line, 2 paragraph
+}
+
+const maxFormDepth = 8
+
+func (ex *pdfExtractor) run(content []byte, resources pdfDict, ctm matrix, depth int) {
+	f := ex.file
+	var stack []pdfGraphicsState
+	gs := pdfGraphicsState{ctm: ctm}
+
+	var font *pdfFont
+	var tm, tlm matrix
+	var fontSize, charSpace, wordSpace, leading, rise float64
+	hscale := 1.0
+
+	lx := &pdfLexer{data: content}
+	var operands []any
+	for !lx.eof() {
+		tok := lx.next()
+		op, ok := tok.(pdfKeyword)
+		if !ok {
+			operands = append(operands, tok)
+			continue
+		}
+
+		num := func(i int) float64 {
+			if i < len(operands) {
+				n, _ := operands[i].(float64)
+				return n
+			}
+			return 0
+		}
+		nextLine := func(tx, ty float64) {
+			tlm = translate(tx, ty).mul(tlm)
+			tm = tlm
+		}
+		show := func(s []byte) {
+			if font == nil {
+				return
+			}
+			for _, g := range font.decode(s) {
+				trm := matrix{fontSize * hscale, 0, 0, fontSize, 0, rise}.mul(tm).mul(gs.ctm)
+				ex.emit(g.text, trm)
+				tx := g.width*fontSize + charSpace
+				if g.space {
+					tx += wordSpace
+				}
+				tm = translate(tx*hscale, 0).mul(tm)
+				end := matrix{fontSize * hscale, 0, 0, fontSize, 0, rise}.mul(tm).mul(gs.ctm)
+				ex.lastX, ex.lastY = end[4], end[5]
+			}
+		}
+
+		switch op {
+		case "q":
+			stack = append(stack, gs)
+		case "Q":
+			if len(stack) > 0 {
+				gs = stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+			}
+		case "cm":
+			if len(operands) >= 6 {
+				gs.ctm = matrix{num(0), num(1), num(2), num(3), num(4), num(5)}.mul(gs.ctm)
+			}
+		case "BT":
+			tm, tlm = identityMatrix, identityMatrix
+		case "Tf":
+			if len(operands) >= 2 {
+				name, _ := operands[0].(pdfName)
+				font = ex.font(resources, name)
+				fontSize = num(1)
+			}
+		case "Tc":
+			charSpace = num(0)
+		case "Tw":
+			wordSpace = num(0)
+		case "Tz":
+			hscale = num(0) / 100
+		case "TL":
+			leading = num(0)
+		case "Ts":
+			rise = num(0)
+		case "Td":
+			nextLine(num(0), num(1))
+		case "TD":
+			leading = -num(1)
+			nextLine(num(0), num(1))
+		case "Tm":
+			if len(operands) >= 6 {
+				tlm = matrix{num(0), num(1), num(2), num(3), num(4), num(5)}
+				tm = tlm
+			}
+		case "T*":
+			nextLine(0, -leading)
+		case "Tj":
+			if len(operands) > 0 {
+				s, _ := operands[0].([]byte)
+				show(s)
+			}
+		case "'":
+			nextLine(0, -leading)
+			if len(operands) > 0 {
+				s, _ := operands[0].([]byte)
+				show(s)
+			}
+		case "\"":
+			if len(operands) >= 3 {
+				wordSpace, charSpace = num(0), num(1)
+				nextLine(0, -leading)
+				s, _ := operands[2].([]byte)
+				show(s)
+			}
+		case "TJ":
+			if len(operands) == 0 {
+				break
+			}
+			arr, _ := operands[0].(pdfArray)
+			for _, el := range arr {
+				switch v := el.(type) {
+				case []byte:
+					show(v)
+				case float64:
+					tx := -v / 1000 * fontSize * hscale
+					tm = translate(tx, 0).mul(tm)
+					// A large negative kern is how many producers write a space
+					if v < -250 {
+						ex.space()
+					}
+				}
+			}
+		case "Do":
+			if len(operands) == 0 || depth >= maxFormDepth {
+				break
+			}
+			name, _ := operands[0].(pdfName)
+			xobjects := f.dict(resources[pdfName("XObject")])
+			stm, ok := f.resolve(xobjects[name]).(pdfStream)
+			if !ok || stm.Dict[pdfName("Subtype")] != pdfName("Form") {
+				break
+			}
+			data, err := f.decodeStream(stm)
+			if err != nil {
+				break
+			}
+			formCTM := gs.ctm
+			if m := f.array(stm.Dict[pdfName("Matrix")]); len(m) == 6 {
+				var fm matrix
+				for i := range fm {
+					fm[i], _ = f.number(m[i])
+				}
+				formCTM = fm.mul(gs.ctm)
+			}
+			formRes := f.dict(stm.Dict[pdfName("Resources")])
+			if formRes == nil {
+				formRes = resources
+			}
+			ex.run(data, formRes, formCTM, depth+1)
+		case "BI":
+			lx.skipInlineImage()
+		}
+		operands = operands[:0]
+	}
+}
+
+// space records a word break at the current position
+func (ex *pdfExtractor) space() {
+	if ex.out.Len() > 0 && !strings.HasSuffix(ex.out.String(), " ") && ex.pendingBreak == 0 {
+		ex.out.WriteByte(' ')
+	}
+}
+
+// emit writes the text of one glyph drawn with text rendering matrix trm,
+// inserting spaces and line breaks based on its distance from the previous glyph
+func (ex *pdfExtractor) emit(text string, trm matrix) {
+	x, y := trm[4], trm[5]
+	size := math.Hypot(trm[2], trm[3])
+	if size == 0 {
+		size = 1
+	}
+
+	if ex.started {
+		dy := ex.lastY - y
+		dx := x - ex.lastX
+		switch {
+		case dy > 1.8*size || dy < -3*size:
+			// Large drop, or a jump back up to a new column
+			ex.pendingBreak = 2
+		case math.Abs(dy) > 0.5*size:
+			if ex.pendingBreak < 1 {
+				ex.pendingBreak = 1
+			}
+		case dx > 0.15*size || dx < -size:
+			ex.space()
+		}
+	}
+	ex.started = true
+
+	if text == "" {
+		return
+	}
+	if ex.pendingBreak > 0 {
+		ex.writeBreak(text)
+	}
+	if text == " " && strings.HasSuffix(ex.out.String(), " ") {
+		return
+	}
+	ex.out.WriteString(text)
+}
+
+// writeBreak writes a pending line or paragraph break before text,
+// rejoining words that were hyphenated across lines
+func (ex *pdfExtractor) writeBreak(next string) {
+	kind := ex.pendingBreak
+	ex.pendingBreak = 0
+	s := strings.TrimRight(ex.out.String(), " ")
+	if s != ex.out.String() {
+		ex.out.Reset()
+		ex.out.WriteString(s)
+	}
+	if s == "" {
+		return
+	}
+
+	if kind == 1 && strings.HasSuffix(s, "-") {
+		before, _ := utf8.DecodeLastRuneInString(s[:len(s)-1])
+		after, _ := utf8.DecodeRuneInString(next)
+		if unicode.IsLetter(before) && unicode.IsLower(after) {
+			ex.out.Reset()
+			ex.out.WriteString(s[:len(s)-1])
+			return
+		}
+	}
+	if kind == 2 {
+		ex.out.WriteString("\n\n")
+	} else {
+		ex.out.WriteByte('\n')
+	}
+}
+
+// font loads (and caches) the font named in a resource dictionary
+func (ex *pdfExtractor) font(resources pdfDict, name pdfName) *pdfFont {
+	fonts := ex.file.dict(resources[pdfName("Font")])
+	obj := fonts[name]
+	ref, isRef := obj.(pdfRef)
+	if isRef {
+		if cached, ok := ex.fonts[ref]; ok {
+			return cached
+		}
+	}
+	font := loadPDFFont(ex.file, ex.file.dict(obj))
+	if isRef {
+		ex.fonts[ref] = font
+	}
+	return font
+}
+
+// pdfGlyph is one decoded character code
+type pdfGlyph struct {
+	text  string
+	width float64 // Advance in text space units (1/1000 em scaled to 1)
+	space bool    // Single-byte code 32, which receives word spacing
+}
+
+type codespaceRange struct {
+	low, high []byte
+}
+
+// pdfFont maps character codes to Unicode text and glyph widths
+type pdfFont struct {
+	composite    bool
+	codespace    []codespaceRange
+	toUnicode    map[string]string // Keyed by the raw code bytes
+	encoding     [256]rune
+	widths       map[int]float64
+	defaultWidth float64
+	widthScale   float64
+}
+
+func loadPDFFont(f *pdfFile, dict pdfDict) *pdfFont {
+	font := &pdfFont{
+		widths:       make(map[int]float64),
+		defaultWidth: 500,
+		widthScale:   0.001,
+		encoding:     standardEncoding,
+	}
+	if dict == nil {
+		return font
+	}
+
+	subtype := f.resolve(dict[pdfName("Subtype")])
+	font.composite = subtype == pdfName("Type0")
+
+	if font.composite {
+		font.codespace = []codespaceRange{{low: []byte{0, 0}, high: []byte{0xff, 0xff}}}
+		if descendants := f.array(dict[pdfName("DescendantFonts")]); len(descendants) > 0 {
+			cid := f.dict(descendants[0])
+			if dw, ok := f.number(cid[pdfName("DW")]); ok {
+				font.defaultWidth = dw
+			} else {
+				font.defaultWidth = 1000
+			}
+			font.readCIDWidths(f, f.array(cid[pdfName("W")]))
+		}
+	} else {
+		font.readSimpleEncoding(f, dict)
+		first := f.int(dict[pdfName("FirstChar")])
+		for i, w := range f.array(dict[pdfName("Widths")]) {
+			if n, ok := f.number(w); ok {
+				font.widths[first+i] = n
+			}
+		}
+		if subtype == pdfName("Type3") {
+			if m := f.array(dict[pdfName("FontMatrix")]); len(m) > 0 {
+				if a, ok := f.number(m[0]); ok {
+					font.widthScale = a
+				}
+			}
+		}
+		if len(font.widths) > 0 {
+			font.defaultWidth = 0
+		}
+	}
+
+	if stm, ok := f.resolve(dict[pdfName("ToUnicode")]).(pdfStream); ok {
+		if data, err := f.decodeStream(stm); err == nil {
+			font.readToUnicode(data)
+		}
+	}
+	return font
+}
+
+func (font *pdfFont) readSimpleEncoding(f *pdfFile, dict pdfDict) {
+	apply := func(name any) {
+		switch f.resolve(name) {
+		case pdfName("WinAnsiEncoding"):
+			font.encoding = winAnsiEncoding
+		case pdfName("MacRomanEncoding"):
+			font.encoding = macRomanEncoding
+		case pdfName("StandardEncoding"):
+			font.encoding = standardEncoding
+		}
+	}
+	switch enc := f.resolve(dict[pdfName("Encoding")]).(type) {
+	case pdfName:
+		apply(enc)
+	case pdfDict:
+		apply(enc[pdfName("BaseEncoding")])
+		code := 0
+		for _, el := range f.array(enc[pdfName("Differences")]) {
+			switch v := f.resolve(el).(type) {
+			case float64:
+				code = int(v)
+			case pdfName:
+				if code >= 0 && code < 256 {
+					if r, ok := glyphNameToRune(string(v)); ok {
+						font.encoding[code] = r
+					}
+				}
+				code++
+			}
+		}
+	}
+}
+
+func (font *pdfFont) readCIDWidths(f *pdfFile, w pdfArray) {
+	for i := 0; i < len(w); {
+		first, ok := f.number(w[i])
+		if !ok || i+1 >= len(w) {
+			return
+		}
+		if list := f.array(w[i+1]); list != nil {
+			for j, width := range list {
+				if n, ok := f.number(width); ok {
+					font.widths[int(first)+j] = n
+				}
+			}
+			i += 2
+			continue
+		}
+		if i+2 >= len(w) {
+			return
+		}
+		last, _ := f.number(w[i+1])
+		width, _ := f.number(w[i+2])
+		for c := int(first); c <= int(last) && c-int(first) < 65536; c++ {
+			font.widths[c] = width
+		}
+		i += 3
+	}
+}
+
+// readToUnicode parses the codespace ranges and bfchar/bfrange mappings of a ToUnicode CMap
+func (font *pdfFont) readToUnicode(data []byte) {
+	font.toUnicode = make(map[string]string)
+	var codespace []codespaceRange
+	lx := &pdfLexer{data: data}
+	var operands []any
+	for !lx.eof() {
+		tok := lx.next()
+		op, ok := tok.(pdfKeyword)
+		if !ok {
+			operands = append(operands, tok)
+			continue
+		}
+		switch op {
+		case "endcodespacerange":
+			for i := 0; i+1 < len(operands); i += 2 {
+				lo, ok1 := operands[i].([]byte)
+				hi, ok2 := operands[i+1].([]byte)
+				if ok1 && ok2 && len(lo) == len(hi) && len(lo) > 0 {
+					codespace = append(codespace, codespaceRange{low: lo, high: hi})
+				}
+			}
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				src, ok1 := operands[i].([]byte)
+				dst, ok2 := operands[i+1].([]byte)
+				if ok1 && ok2 {
+					font.toUnicode[string(src)] = utf16BEString(dst)
+				}
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				lo, ok1 := operands[i].([]byte)
+				hi, ok2 := operands[i+1].([]byte)
+				if !ok1 || !ok2 || len(lo) != len(hi) {
+					continue
+				}
+				start, end := codeValue(lo), codeValue(hi)
+				for c := start; c <= end && c-start < 65536; c++ {
+					code := codeBytes(c, len(lo))
+					switch dst := operands[i+2].(type) {
+					case []byte:
+						font.toUnicode[string(code)] = utf16BEString(incrementCode(dst, c-start))
+					case pdfArray:
+						if c-start < len(dst) {
+							if d, ok := dst[c-start].([]byte); ok {
+								font.toUnicode[string(code)] = utf16BEString(d)
+							}
+						}
+					}
+				}
+			}
+		}
+		operands = operands[:0]
+	}
+	if len(codespace) > 0 {
+		font.codespace = codespace
+	}
+}
+
+func codeValue(b []byte) int {
+	v := 0
+	for _, c := range b {
+		v = v<<8 | int(c)
+	}
+	return v
+}
+
+func codeBytes(v, n int) []byte {
+	b := make([]byte, n)
+	for i := n - 1; i >= 0; i-- {
+		b[i] = byte(v)
+		v >>= 8
+	}
+	return b
+}
+
+// incrementCode adds n to a bfrange destination code
+func incrementCode(dst []byte, n int) []byte {
+	out := append([]byte(nil), dst...)
+	for i := len(out) - 1; i >= 0 && n > 0; i-- {
+		v := int(out[i]) + n
+		out[i] = byte(v)
+		n = v >> 8
+	}
+	return out
+}
+
+func utf16BEString(b []byte) string {
+	if len(b) == 1 {
+		return string(rune(b[0]))
+	}
+	units := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		units = append(units, uint16(b[i])<<8|uint16(b[i+1]))
+	}
+	return strings.Map(func(r rune) rune {
+		if r == 0 {
+			return -1
+		}
+		return r
+	}, string(utf16.Decode(units)))
+}
+
+// nextCode splits the first character code off s according to the codespace
+func (font *pdfFont) nextCode(s []byte) []byte {
+	if len(font.codespace) == 0 {
+		return s[:1]
+	}
+	for n := 1; n <= 4 && n <= len(s); n++ {
+		for _, r := range font.codespace {
+			if len(r.low) != n {
+				continue
+			}
+			in := true
+			for i := 0; i < n; i++ {
+				if s[i] < r.low[i] || s[i] > r.high[i] {
+					in = false
+					break
+				}
+			}
+			if in {
+				return s[:n]
+			}
+		}
+	}
+	if font.composite && len(s) >= 2 {
+		return s[:2]
+	}
+	return s[:1]
+}
+
+func (font *pdfFont) decode(s []byte) []pdfGlyph {
+	var glyphs []pdfGlyph
+	for len(s) > 0 {
+		code := font.nextCode(s)
+		s = s[len(code):]
+		cv := codeValue(code)
+
+		var text string
+		if t, ok := font.toUnicode[string(code)]; ok {
+			text = t
+		} else if !font.composite {
+			if r := font.encoding[code[0]]; r != 0 {
+				text = string(r)
+			}
+		}
+		// Fold ligatures and other compatibility characters (ﬁ → fi)
+		text = norm.NFKC.String(text)
+
+		width, ok := font.widths[cv]
+		if !ok {
+			width = font.defaultWidth
+		}
+		glyphs = append(glyphs, pdfGlyph{
+			text:  text,
+			width: width * font.widthScale,
+			space: len(code) == 1 && code[0] == ' ',
+		})
+	}
+	return glyphs
+}
+
+// Simple font encodings. Only the ranges that differ from Latin-1 are listed;
+// the tables are completed in init.
+var standardEncoding, winAnsiEncoding, macRomanEncoding [256]rune
+
+func init() {
+	for i := 32; i < 127; i++ {
+		standardEncoding[i] = rune(i)
+		winAnsiEncoding[i] = rune(i)
+		macRomanEncoding[i] = rune(i)
+	}
+	standardEncoding['\''] = '’'
+	standardEncoding['`'] = '‘'
+	for code, name := range map[int]string{
+		0xa1: "exclamdown", 0xa2: "cent", 0xa3: "sterling", 0xa5: "yen", 0xa7: "section",
+		0xa9: "quotesingle", 0xaa: "quotedblleft", 0xab: "guillemotleft", 0xae: "fi", 0xaf: "fl",
+		0xb1: "endash", 0xb2: "dagger", 0xb3: "daggerdbl", 0xb4: "periodcentered", 0xb6: "paragraph",
+		0xb7: "bullet", 0xb8: "quotesinglbase", 0xb9: "quotedblbase", 0xba: "quotedblright",
+		0xbb: "guillemotright", 0xbc: "ellipsis", 0xbd: "perthousand", 0xbf: "questiondown",
+		0xd0: "emdash", 0xe1: "AE", 0xe8: "Lslash", 0xe9: "Oslash", 0xea: "OE", 0xf1: "ae",
+		0xf5: "dotlessi", 0xf8: "lslash", 0xf9: "oslash", 0xfa: "oe", 0xfb: "germandbls",
+	} {
+		standardEncoding[code], _ = glyphNameToRune(name)
+	}
+
+	for i := 0xa0; i < 0x100; i++ {
+		winAnsiEncoding[i] = rune(i)
+	}
+	for i, r := range []rune("€\x00‚ƒ„…†‡ˆ‰Š‹Œ\x00Ž\x00\x00‘’“”•–—˜™š›œ\x00žŸ") {
+		winAnsiEncoding[0x80+i] = r
+	}
+
+	for i, r := range []rune("ÄÅÇÉÑÖÜáàâäãåçéèêëíìîïñóòôöõúùûü†°¢£§•¶ß®©™´¨≠ÆØ∞±≤≥¥µ∂∑∏π∫ªºΩæø¿¡¬√ƒ≈∆«»… ÀÃÕŒœ–—“”‘’÷◊ÿŸ⁄€‹›ﬁﬂ‡·‚„‰ÂÊÁËÈÍÎÏÌÓÔÒÚÛÙıˆ˜¯˘˙˚¸˝˛ˇ") {
+		macRomanEncoding[0x80+i] = r
+	}
+}
+
+// Glyph names that are not a single letter, a uniXXXX/uXXXX name or a
+// letter followed by an accent name
+var glyphNames = map[string]rune{
+	"space": ' ', "exclam": '!', "quotedbl": '"', "numbersign": '#', "dollar": '$',
+	"percent": '%', "ampersand": '&', "quotesingle": '\'', "quoteright": '’', "quoteleft": '‘',
+	"parenleft": '(', "parenright": ')', "asterisk": '*', "plus": '+', "comma": ',',
+	"hyphen": '-', "minus": '−', "period": '.', "slash": '/', "colon": ':', "semicolon": ';',
+	"less": '<', "equal": '=', "greater": '>', "question": '?', "at": '@',
+	"bracketleft": '[', "backslash": '\\', "bracketright": ']', "asciicircum": '^',
+	"underscore": '_', "grave": '`', "braceleft": '{', "bar": '|', "braceright": '}',
+	"asciitilde": '~', "zero": '0', "one": '1', "two": '2', "three": '3', "four": '4',
+	"five": '5', "six": '6', "seven": '7', "eight": '8', "nine": '9',
+	"bullet": '•', "endash": '–', "emdash": '—', "quotedblleft": '“', "quotedblright": '”',
+	"quotesinglbase": '‚', "quotedblbase": '„', "ellipsis": '…', "guillemotleft": '«',
+	"guillemotright": '»', "guilsinglleft": '‹', "guilsinglright": '›',
+	"fi": 'ﬁ', "fl": 'ﬂ', "ff": 'ﬀ', "ffi": 'ﬃ', "ffl": 'ﬄ',
+	"dagger": '†', "daggerdbl": '‡', "trademark": '™', "copyright": '©', "registered": '®',
+	"degree": '°', "section": '§', "paragraph": '¶', "periodcentered": '·', "multiply": '×',
+	"divide": '÷', "Euro": '€', "sterling": '£', "yen": '¥', "cent": '¢', "perthousand": '‰',
+	"exclamdown": '¡', "questiondown": '¿', "AE": 'Æ', "ae": 'æ', "OE": 'Œ', "oe": 'œ',
+	"Oslash": 'Ø', "oslash": 'ø', "Lslash": 'Ł', "lslash": 'ł', "germandbls": 'ß',
+	"dotlessi": 'ı', "Eth": 'Ð', "eth": 'ð', "Thorn": 'Þ', "thorn": 'þ', "mu": 'µ',
+	"plusminus": '±', "logicalnot": '¬', "nbspace": ' ', "florin": 'ƒ',
+}
+
+var accentMarks = map[string]rune{
+	"acute": '́', "grave": '̀', "circumflex": '̂', "dieresis": '̈',
+	"tilde": '̃', "ring": '̊', "cedilla": '̧', "caron": '̌',
+	"breve": '̆', "macron": '̄', "ogonek": '̨', "dotaccent": '̇',
+	"hungarumlaut": '̋',
+}
+
+// glyphNameToRune maps a PostScript glyph name to a character
+func glyphNameToRune(name string) (rune, bool) {
+	name, _, _ = strings.Cut(name, ".") // Drop suffixes like "a.sc"
+	if r, ok := glyphNames[name]; ok {
+		return r, true
+	}
+	if utf8.RuneCountInString(name) == 1 {
+		r, _ := utf8.DecodeRuneInString(name)
+		return r, true
+	}
+	if hexCode, ok := strings.CutPrefix(name, "uni"); ok && len(hexCode) >= 4 {
+		if v, err := strconv.ParseUint(hexCode[:4], 16, 32); err == nil {
+			return rune(v), true
+		}
+	}
+	if hexCode, ok := strings.CutPrefix(name, "u"); ok && len(hexCode) >= 4 && len(hexCode) <= 6 {
+		if v, err := strconv.ParseUint(hexCode, 16, 32); err == nil {
+			return rune(v), true
+		}
+	}
+	// Accented letters are named base letter + accent ("eacute")
+	for accent, mark := range accentMarks {
+		if base, ok := strings.CutSuffix(name, accent); ok && utf8.RuneCountInString(base) == 1 {
+			composed := norm.NFC.String(base + string(mark))
+			r, _ := utf8.DecodeRuneInString(composed)
+			return r, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// punctClass is the kind of break the punctuation at the end of a word marks
+type punctClass int
+
+const (
+	punctNone     punctClass = iota
+	punctClause              // Commas, semicolons, colons, dashes and bare closing quotes
+	punctSentence            // Full stops, question and exclamation marks, ellipses
+)
+
+// Abbreviations whose full stop doesn't end a sentence, lowercased and
+// without the final stop. Ones that often end sentences, like "etc.", are
+// left out.
+var abbreviations = map[string]bool{
+	"mr": true, "mrs": true, "ms": true, "mx": true, "dr": true, "prof": true,
+	"rev": true, "hon": true, "sr": true, "jr": true, "st": true, "mt": true,
+	"gen": true, "col": true, "lt": true, "sgt": true, "capt": true, "gov": true,
+	"sen": true, "vs": true, "cf": true, "fig": true, "figs": true, "vol": true,
+	"vols": true, "ch": true, "pp": true, "approx": true, "ca": true,
+}
+
+// isCloser reports whether r closes a quotation or bracket, which can
+// follow the punctuation that ends a clause or sentence: "Stop." (see above.)
+func isCloser(r rune) bool {
+	return isClosingQuote(r) || unicode.Is(unicode.Pe, r)
+}
+
+// isClosingQuote reports whether r closes a quotation at the end of a word.
+// Besides closing marks and the ASCII " and ', that includes “ and ‘, which
+// close German quotations („Ja.“); opening marks like „ and « don't count.
+func isClosingQuote(r rune) bool {
+	return r == '"' || r == '\'' || r == '“' || r == '‘' || unicode.Is(unicode.Pf, r)
+}
+
+// classifyPunct finds the break that word ends, looking past closing quotes
+// and brackets to the last mark before them
+func classifyPunct(word string) punctClass {
+	body := strings.TrimRightFunc(word, isCloser)
+	closed := len(body) < len(word)
+	last, _ := utf8.DecodeLastRuneInString(body)
+
+	switch {
+	case body == "":
+		return punctNone
+	case last == '.':
+		if isAbbreviation(body) {
+			return punctNone
+		}
+		return punctSentence
+	case last == '…' || unicode.Is(unicode.Sentence_Terminal, last):
+		return punctSentence
+	case unicode.In(last, unicode.Terminal_Punctuation, unicode.Pd):
+		return punctClause
+	case closed:
+		return punctClause
+	}
+	return punctNone
+}
+
+// isAbbreviation reports whether word, ending in a full stop, is an
+// abbreviation: a known short form or dotted letters ("e.g.", "U.S.").
+// A lone capital isn't taken for an initial, since "I." and "A." end
+// sentences as often as "J." starts a name.
+func isAbbreviation(word string) bool {
+	word = strings.TrimLeftFunc(word, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	stem := strings.TrimSuffix(word, ".")
+	if abbreviations[strings.ToLower(stem)] {
+		return true
+	}
+	parts := strings.Split(stem, ".")
+	if len(parts) < 2 {
+		return false
+	}
+	for _, part := range parts {
+		if utf8.RuneCountInString(part) != 1 || !unicode.IsLetter([]rune(part)[0]) {
+			return false
+		}
+	}
+	return true
+}
+
+func endsWithPunctuation(word string) bool {
+	return classifyPunct(word) != punctNone
+}
+
+func endsWithSentence(word string) bool {
+	return classifyPunct(word) == punctSentence
+}
+
+// endsWithQuote reports whether word closes a quotation
+func endsWithQuote(word string) bool {
+	for len(word) > 0 {
+		r, size := utf8.DecodeLastRuneInString(word)
+		if !isCloser(r) {
+			return false
+		}
+		if isClosingQuote(r) {
+			return true
+		}
+		word = word[:len(word)-size]
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package main
+
+import "testing"
+
+func TestClassifyPunct(t *testing.T) {
+	tests := []struct {
+		word string
+		want punctClass
+	}{
+		{"word", punctNone},
+		{"word,", punctClause},
+		{"word;", punctClause},
+		{"word—", punctClause},
+		{`"quoted"`, punctClause}, // A bare closing quote
+		{"Stop.", punctSentence},
+		{`"Stop."`, punctSentence},
+		{"“Stop!”", punctSentence},
+		{"(see above).", punctSentence},
+		{"(see above.)", punctSentence},
+		{"wait…", punctSentence},
+		{"wait...", punctSentence},
+		{"Really?", punctSentence},
+		{"。", punctSentence},
+		{"終わり。", punctSentence},
+		{"？", punctSentence},
+		{"Dr.", punctNone},
+		{"(Dr.", punctNone},
+		{"e.g.", punctNone},
+		{"U.S.", punctNone},
+		{"J.K.", punctNone},
+		{"I.", punctSentence},
+		{"A.", punctSentence},
+		{"etc.", punctSentence}, // Left out of the list: it often ends sentences
+		{"«Non", punctNone},     // Opening quotes don't close anything
+		{"„Ja", punctNone},
+		{"„Ja.“", punctSentence}, // German quotes close with “
+		{"„Ja“,", punctClause},
+		{"„Ja“", punctClause},
+		{"‚ja.‘", punctSentence},
+		{"", punctNone},
+	}
+	for _, tt := range tests {
+		if got := classifyPunct(tt.word); got != tt.want {
+			t.Errorf("classifyPunct(%q) = %d, want %d", tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestEndsWithQuote(t *testing.T) {
+	tests := []struct {
+		word string
+		want bool
+	}{
+		{`"Stop."`, true},
+		{"Stop.”", true},
+		{"said.'", true},
+		{"Stop.", false},
+		{"(see above.)", false},
+		{`(quoted")`, true},
+		{"«Non", false},
+		{"„Ja.“", true},
+		{"‚ja.‘", true},
+	}
+	for _, tt := range tests {
+		if got := endsWithQuote(tt.word); got != tt.want {
+			t.Errorf("endsWithQuote(%q) = %v, want %v", tt.word, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"math"
+	"strings"
+)
+
+// Ways of drawing rendered text in the terminal
+var renderModes = []string{"blocks", "halfblock", "braille", "image", "kitty", "iterm2", "sixel"}
+
+// Subpixels per terminal cell for each sub-cell render mode
+var subpixelGrid = map[string]struct{ cols, rows int }{
+	"halfblock": {1, 2},
+	"braille":   {2, 4},
+}
+
+// Braille dot bits by subpixel position: brailleDots[row][col]
+var brailleDots = [4][2]rune{
+	{0x01, 0x08},
+	{0x02, 0x10},
+	{0x04, 0x20},
+	{0x40, 0x80},
+}
+
+// rasterize treats every non-space cell of lines as a pixel and redraws the
+// bitmap at a fractional scale, using half-block or braille characters so
+// that each terminal cell holds several pixels. A subpixel is set when the
+// source pixels cover at least half of it, which keeps stroke widths even.
+func rasterize(lines []string, scale float64, mode string) []string {
+	grid, ok := subpixelGrid[mode]
+	if !ok || len(lines) == 0 {
+		return lines
+	}
+
+	src := make([][]bool, len(lines))
+	srcWidth := 0
+	for y, line := range lines {
+		for _, r := range line {
+			src[y] = append(src[y], r != ' ')
+		}
+		srcWidth = max(srcWidth, len(src[y]))
+	}
+	srcHeight := len(src)
+	if srcWidth == 0 {
+		return lines
+	}
+
+	width := max(int(math.Round(float64(srcWidth)*scale)), 1)
+	height := max(int(math.Round(float64(srcHeight)*scale)), 1)
+	subWidth, subHeight := width*grid.cols, height*grid.rows
+
+	// Size of one subpixel in source pixels
+	stepX := float64(srcWidth) / float64(subWidth)
+	stepY := float64(srcHeight) / float64(subHeight)
+	pixel := func(x, y int) bool {
+		return y < len(src) && x < len(src[y]) && src[y][x]
+	}
+	covered := func(sx, sy int) bool {
+		x0, x1 := float64(sx)*stepX, float64(sx+1)*stepX
+		y0, y1 := float64(sy)*stepY, float64(sy+1)*stepY
+		area := 0.0
+		for y := int(y0); float64(y) < y1; y++ {
+			h := math.Min(y1, float64(y+1)) - math.Max(y0, float64(y))
+			for x := int(x0); float64(x) < x1; x++ {
+				if pixel(x, y) {
+					area += h * (math.Min(x1, float64(x+1)) - math.Max(x0, float64(x)))
+				}
+			}
+		}
+		return area >= stepX*stepY/2
+	}
+
+	out := make([]string, height)
+	for cy := range out {
+		var sb strings.Builder
+		for cx := 0; cx < width; cx++ {
+			switch mode {
+			case "halfblock":
+				top, bottom := covered(cx, cy*2), covered(cx, cy*2+1)
+				switch {
+				case top && bottom:
+					sb.WriteRune('█')
+				case top:
+					sb.WriteRune('▀')
+				case bottom:
+					sb.WriteRune('▄')
+				default:
+					sb.WriteByte(' ')
+				}
+			case "braille":
+				var dots rune
+				for row := range 4 {
+					for col := range 2 {
+						if covered(cx*2+col, cy*4+row) {
+							dots |= brailleDots[row][col]
+						}
+					}
+				}
+				if dots == 0 {
+					sb.WriteByte(' ')
+				} else {
+					sb.WriteRune(0x2800 + dots)
+				}
+			}
+		}
+		out[cy] = sb.String()
+	}
+	return out
+}
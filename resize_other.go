@@ -0,0 +1,26 @@
+//go:build !unix
+
+package main
+
+import "time"
+
+// watchResize reports terminal resizes. There is no SIGWINCH here, so the
+// size is polled instead.
+func watchResize() <-chan struct{} {
+	resized := make(chan struct{}, 1)
+	go func() {
+		width, height := getTerminalSize()
+		for range time.Tick(250 * time.Millisecond) {
+			w, h := getTerminalSize()
+			if w == width && h == height {
+				continue
+			}
+			width, height = w, h
+			select {
+			case resized <- struct{}{}:
+			default:
+			}
+		}
+	}()
+	return resized
+}
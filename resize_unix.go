@@ -0,0 +1,26 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// watchResize reports terminal resizes as the terminal signals them with
+// SIGWINCH. Resizes that arrive before the last one is handled are merged.
+func watchResize() <-chan struct{} {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGWINCH)
+	resized := make(chan struct{}, 1)
+	go func() {
+		for range sig {
+			select {
+			case resized <- struct{}{}:
+			default:
+			}
+		}
+	}()
+	return resized
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"math"
+	"time"
+)
+
+// Lateness past which the scheduler stops catching up and starts timing
+// from now, e.g. after the process was stopped or the terminal stalled
+const maxLag = 500 * time.Millisecond
+
+// scheduler paces words against deadlines. Each word is due a fixed delay
+// after the previous word's deadline rather than after it was drawn, so the
+// time spent rendering and writing frames is absorbed instead of adding up.
+type scheduler struct {
+	deadline time.Time     // When the current word is due to give way
+	last     time.Time     // When the previous word actually gave way
+	pending  time.Duration // Delay of the current word, counted once it gives way
+
+	flashes   int           // Times the words on screen changed
+	words     int           // Words shown in those flashes
+	scheduled time.Duration // Total of the delays words were given
+	elapsed   time.Duration // Time words were actually on screen
+	lateSum   float64       // Sum of lateness at each deadline, in seconds
+	lateSqSum float64       // Sum of squared lateness, for the jitter
+}
+
+// start times the next word from now. It is called when reading begins and
+// again on resuming from a pause.
+func (s *scheduler) start(now time.Time) {
+	s.deadline = now
+	s.last = now
+	s.pending = 0
+}
+
+// next schedules a word shown for delay and returns its deadline
+func (s *scheduler) next(delay time.Duration) time.Time {
+	s.deadline = s.deadline.Add(delay)
+	s.pending = delay
+	return s.deadline
+}
+
+// arrived records that the current flash of words gave way at now
+func (s *scheduler) arrived(now time.Time, words int) {
+	late := now.Sub(s.deadline)
+	s.flashes++
+	s.words += words
+	s.scheduled += s.pending
+	s.pending = 0
+	s.elapsed += now.Sub(s.last)
+	s.lateSum += late.Seconds()
+	s.lateSqSum += late.Seconds() * late.Seconds()
+	s.last = now
+	if late > maxLag {
+		s.deadline = now
+	}
+}
+
+// targetWPM is the reading speed the schedule asked for, including the
+// extra time given to long words and punctuation
+func (s *scheduler) targetWPM() int {
+	return wordsPerMinute(s.words, s.scheduled)
+}
+
+// achievedWPM is the reading speed actually delivered
+func (s *scheduler) achievedWPM() int {
+	return wordsPerMinute(s.words, s.elapsed)
+}
+
+// drift is how far behind schedule reading finished overall
+func (s *scheduler) drift() time.Duration {
+	return s.elapsed - s.scheduled
+}
+
+// jitter is the standard deviation of how late words gave way
+func (s *scheduler) jitter() time.Duration {
+	if s.flashes == 0 {
+		return 0
+	}
+	n := float64(s.flashes)
+	mean := s.lateSum / n
+	variance := max(s.lateSqSum/n-mean*mean, 0)
+	return time.Duration(math.Sqrt(variance) * float64(time.Second))
+}
+
+func wordsPerMinute(words int, d time.Duration) int {
+	if d.Minutes() <= 0 {
+		return 0
+	}
+	return int(math.Round(float64(words) / d.Minutes()))
+}
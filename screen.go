@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// cell is one character on screen with the SGR attributes it is drawn with.
+// A wide character takes two cells, the second holding ch 0.
+type cell struct {
+	ch    rune
+	style string // Escape sequences to apply after a reset, "" for plain text
+}
+
+var blankCell = cell{ch: ' '}
+
+// screen draws frames on the alternate screen. It keeps the last frame and
+// writes only the cells that changed, in a single write, so fast reading
+// doesn't flicker.
+type screen struct {
+	mu     sync.Mutex
+	out    io.Writer
+	active bool
+	cells  [][]cell // What is on the terminal now, nil after invalidate
+	raw    []string // Lines holding graphics escapes, drawn as-is
+}
+
+func newScreen(out io.Writer) *screen {
+	return &screen{out: out}
+}
+
+// enter switches to the alternate screen and hides the cursor
+func (s *screen) enter() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fmt.Fprint(s.out, "\033[?1049h\033[?25l")
+	s.active = true
+	s.cells = nil
+}
+
+// exit restores the cursor and the normal screen. Later draws are ignored,
+// so it is safe to call from another goroutine while frames are drawn.
+func (s *screen) exit() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.active {
+		return
+	}
+	fmt.Fprint(s.out, "\033[0m\033[?25h\033[?1049l")
+	s.active = false
+}
+
+// Write sends escape sequences that aren't part of a frame, such as deleting
+// images, without interleaving them with a frame being drawn. They are
+// dropped once the screen has been exited.
+func (s *screen) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.active {
+		return len(p), nil
+	}
+	return s.out.Write(p)
+}
+
+// invalidate makes the next draw repaint the whole screen
+func (s *screen) invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cells = nil
+}
+
+// draw shows lines as the next frame. Lines past the bottom of the screen
+// push the frame up, as printing them would have scrolled.
+func (s *screen) draw(lines []string, width, height int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.active {
+		return
+	}
+	if len(lines) > height {
+		lines = lines[len(lines)-height:]
+	}
+
+	next := make([][]cell, height)
+	raw := make([]string, height)
+	for y := range next {
+		line := ""
+		if y < len(lines) {
+			line = lines[y]
+		}
+		next[y], raw[y] = parseCells(line, width)
+	}
+
+	var buf bytes.Buffer
+	full := s.cells == nil || len(s.cells) != height || len(s.cells[0]) != width
+	if !full {
+		// Inline images can't be diffed by cell, so a changed image repaints
+		// everything to clear the old one
+		for y := range raw {
+			if raw[y] != s.raw[y] {
+				full = true
+				break
+			}
+		}
+	}
+	if full {
+		buf.WriteString("\033[0m\033[2J")
+		s.cells = make([][]cell, height)
+		for y := range s.cells {
+			s.cells[y] = make([]cell, width)
+			for x := range s.cells[y] {
+				s.cells[y][x] = blankCell
+			}
+		}
+	}
+
+	style := ""
+	cursorY, cursorX := -1, -1
+	for y := range next {
+		for x, c := range next[y] {
+			if c == s.cells[y][x] || c.ch == 0 {
+				// Unchanged, or the right half of a wide character drawn with it
+				continue
+			}
+			if y != cursorY || x != cursorX {
+				fmt.Fprintf(&buf, "\033[%d;%dH", y+1, x+1)
+			}
+			if c.style != style {
+				buf.WriteString("\033[0m" + c.style)
+				style = c.style
+			}
+			buf.WriteRune(c.ch)
+			cursorY, cursorX = y, x+runewidth.RuneWidth(c.ch)
+		}
+	}
+	if style != "" {
+		buf.WriteString("\033[0m")
+	}
+	for y, r := range raw {
+		if r != "" && (full || r != s.raw[y]) {
+			fmt.Fprintf(&buf, "\033[%d;1H%s", y+1, r)
+		}
+	}
+
+	s.cells, s.raw = next, raw
+	if buf.Len() > 0 {
+		s.out.Write(buf.Bytes())
+	}
+}
+
+// parseCells splits a line into width columns of cells, applying SGR color
+// and attribute sequences. The rest of a line from the first other escape
+// sequence on (an inline image) is returned separately, with the line's
+// leading spaces so it keeps its column.
+func parseCells(line string, width int) ([]cell, string) {
+	cells := make([]cell, 0, width)
+	style := ""
+	raw := ""
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if r == '\033' {
+			// CSI ... m sets attributes; anything else is passed through
+			end := i + 1
+			if end < len(runes) && runes[end] == '[' {
+				end++
+				for end < len(runes) && (runes[end] >= '0' && runes[end] <= '9' || runes[end] == ';') {
+					end++
+				}
+				if end < len(runes) && runes[end] == 'm' {
+					params := string(runes[i+2 : end])
+					if params == "" || params == "0" {
+						style = ""
+					} else {
+						style += "\033[" + params + "m"
+					}
+					i = end
+					continue
+				}
+			}
+			raw = strings.Repeat(" ", len(cells)) + string(runes[i:])
+			break
+		}
+		w := runewidth.RuneWidth(r)
+		if r < ' ' || w == 0 {
+			continue
+		}
+		if len(cells)+w <= width {
+			cells = append(cells, cell{ch: r, style: style})
+			if w == 2 {
+				cells = append(cells, cell{style: style})
+			}
+		}
+	}
+	for len(cells) < width {
+		cells = append(cells, blankCell)
+	}
+	return cells, raw
+}
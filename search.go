@@ -0,0 +1,214 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// searchIndex is the document's words joined by spaces, so that phrases
+// can be found across words, with where each word starts in it. Long words
+// split into pieces are joined back up without the hyphens added to them,
+// so they are found whole.
+type searchIndex struct {
+	text   string
+	starts []int // Byte offset of each word in text
+}
+
+func newSearchIndex(words []token) *searchIndex {
+	var sb strings.Builder
+	starts := make([]int, len(words))
+	for i, w := range words {
+		if i > 0 && !words[i-1].Continued {
+			sb.WriteByte(' ')
+		}
+		starts[i] = sb.Len()
+		if w.Hyphenated {
+			sb.WriteString(strings.TrimSuffix(w.Text, "-"))
+		} else {
+			sb.WriteString(w.Text)
+		}
+	}
+	return &searchIndex{text: sb.String(), starts: starts}
+}
+
+// compileQuery turns a search into a regular expression. Literal searches
+// match the text as typed. Either kind ignores case unless the query has
+// a capital letter in it.
+func compileQuery(query string, regex bool) (*regexp.Regexp, error) {
+	caseless := !hasCapital(query, regex)
+	if !regex {
+		query = regexp.QuoteMeta(query)
+	}
+	if caseless {
+		query = "(?i)" + query
+	}
+	return regexp.Compile(query)
+}
+
+// hasCapital reports whether query has a capital letter in it. In a regular
+// expression, the letters of escapes such as \S, \W and \p{Lu} don't count.
+func hasCapital(query string, regex bool) bool {
+	runes := []rune(query)
+	for i := 0; i < len(runes); i++ {
+		if regex && runes[i] == '\\' && i+1 < len(runes) {
+			i++
+			if runes[i] == 'p' || runes[i] == 'P' {
+				// Skip the class name, one letter or in braces
+				if i+1 < len(runes) && runes[i+1] == '{' {
+					for i < len(runes)-1 && runes[i] != '}' {
+						i++
+					}
+				} else {
+					i++
+				}
+			}
+			continue
+		}
+		if unicode.IsUpper(runes[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// wordAt returns the index of the word containing byte offset off of text
+func (s *searchIndex) wordAt(off int) int {
+	return max(sort.SearchInts(s.starts, off+1)-1, 0)
+}
+
+// find returns the words [start, end) of the first match starting in or
+// after word from, or with backward the last match before it. The search
+// wraps around the ends of the document. Matches are found in the whole
+// text, so ^ and \b don't match just because the search starts at word from.
+func (s *searchIndex) find(re *regexp.Regexp, from int, backward bool) (start, end int, ok bool) {
+	var matches [][]int
+	for _, loc := range re.FindAllStringIndex(s.text, -1) {
+		// A match starting on the space before a word starts at the word
+		for loc[0] < loc[1] && s.text[loc[0]] == ' ' {
+			loc[0]++
+		}
+		if loc[0] < loc[1] {
+			matches = append(matches, loc)
+		}
+	}
+	if len(matches) == 0 {
+		return 0, 0, false
+	}
+
+	// The first match starting in or after word from, or the one before it
+	next := sort.Search(len(matches), func(k int) bool { return matches[k][0] >= s.starts[from] })
+	if backward {
+		next--
+	}
+	loc := matches[(next+len(matches))%len(matches)]
+	return s.wordAt(loc[0]), s.wordAt(loc[1]-1) + 1, true
+}
+
+// searchState is the search prompt and the match being shown, shared by
+// the input goroutine and the render loop
+type searchState struct {
+	mu      sync.Mutex
+	editing bool // The prompt is open
+	query   string
+	regex   bool
+	start   int    // First word of the match shown, -1 for none
+	end     int    // Word after the match
+	message string // Shown in the status line, such as "Pattern not found"
+}
+
+// searchView is a copy of the search state for drawing a frame
+type searchView struct {
+	Editing    bool
+	Query      string
+	Regex      bool
+	Start, End int
+	Message    string
+}
+
+func (s *searchState) view() searchView {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return searchView{s.editing, s.query, s.regex, s.start, s.end, s.message}
+}
+
+// prompt is the search line as shown at the bottom of the screen
+func (v searchView) prompt() string {
+	if v.Regex {
+		return "regex /" + v.Query + "█"
+	}
+	return "/" + v.Query + "█"
+}
+
+// contextLine shows up to six words either side of the match, as many as
+// fit in width, with the match in reverse video
+func (v searchView) contextLine(words []token, width int) string {
+	from, to := v.Start, v.End
+	for around := 6; around > 0; around-- {
+		from, to = max(v.Start-around, 0), min(v.End+around, len(words))
+		n := to - from - 1
+		for _, w := range words[from:to] {
+			n += utf8.RuneCountInString(w.Text)
+		}
+		if n <= width {
+			break
+		}
+	}
+	var plain, styled strings.Builder
+	for i := from; i < to; i++ {
+		if i > from {
+			plain.WriteByte(' ')
+			styled.WriteByte(' ')
+		}
+		plain.WriteString(words[i].Text)
+		if i >= v.Start && i < v.End {
+			styled.WriteString("\033[7m" + words[i].Text + "\033[0m")
+		} else {
+			styled.WriteString("\033[2m" + words[i].Text + "\033[0m")
+		}
+	}
+	padding := max((width-len([]rune(plain.String())))/2, 0)
+	return strings.Repeat(" ", padding) + styled.String()
+}
+
+// run searches for the current query from word from and records the
+// match, or why there is none
+func (s *searchState) run(index *searchIndex, from int, backward bool) (start int, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.start, s.end, s.message = -1, -1, ""
+	if s.query == "" {
+		return 0, false
+	}
+	re, err := compileQuery(s.query, s.regex)
+	if err != nil {
+		s.message = fmt.Sprintf("Invalid pattern: %v", err)
+		return 0, false
+	}
+	start, end, ok := index.find(re, from, backward)
+	if !ok {
+		s.message = "Pattern not found: " + s.query
+		return 0, false
+	}
+	s.start, s.end = start, end
+	return start, true
+}
+
+// edit changes the prompt with fn, which returns false to close it
+func (s *searchState) edit(fn func(s *searchState) bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.editing = fn(s)
+}
+
+// clear forgets the match shown and any message, keeping the query for
+// the next search
+func (s *searchState) clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.start, s.end, s.message = -1, -1, ""
+}
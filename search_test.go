@@ -0,0 +1,96 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func testSearchIndex(text string) *searchIndex {
+	var words []token
+	for _, w := range strings.Fields(text) {
+		words = append(words, token{Text: w})
+	}
+	return newSearchIndex(words)
+}
+
+func TestFind(t *testing.T) {
+	// Words: 0 foo, 1 bar, 2 foo, 3 baz, 4 bar, 5 qux
+	index := testSearchIndex("foo bar foo baz bar qux")
+	tests := []struct {
+		query      string
+		regex      bool
+		from       int
+		backward   bool
+		start, end int
+	}{
+		{"foo", false, 1, false, 2, 3},
+		{"foo", false, 3, false, 0, 1}, // Wraps around the end
+		{"foo", false, 2, true, 0, 1},
+		{"foo", false, 0, true, 2, 3}, // Wraps around the start
+		{"baz bar", false, 0, false, 3, 5},
+		{"^foo", true, 1, false, 0, 1}, // Only the start of the text
+		{"^foo", true, 2, false, 0, 1},
+		{`\bba`, true, 2, false, 3, 4},
+		{" bar", false, 2, false, 4, 5}, // The space before a word
+		{" bar", false, 0, false, 1, 2},
+		{`\sbaz`, true, 0, false, 3, 4},
+		{"qux$", true, 0, false, 5, 6},
+	}
+	for _, tt := range tests {
+		re, err := compileQuery(tt.query, tt.regex)
+		if err != nil {
+			t.Fatalf("compileQuery(%q): %v", tt.query, err)
+		}
+		start, end, ok := index.find(re, tt.from, tt.backward)
+		if !ok || start != tt.start || end != tt.end {
+			t.Errorf("find(%q, from %d, backward %v) = [%d, %d) %v, want [%d, %d)",
+				tt.query, tt.from, tt.backward, start, end, ok, tt.start, tt.end)
+		}
+	}
+
+	for _, query := range []string{"nothing", "^bar", "^", `\bar`} {
+		re, _ := compileQuery(query, true)
+		if start, end, ok := index.find(re, 0, false); ok {
+			t.Errorf("find(%q) = [%d, %d), want no match", query, start, end)
+		}
+	}
+}
+
+func TestFindSplitWords(t *testing.T) {
+	doc := &document{Tokens: []token{
+		{Text: "the"},
+		{Text: "internationalization", Offset: 4},
+		{Text: "matters", Offset: 25},
+	}}
+	doc.splitLongWords(8, nil)
+	index := newSearchIndex(doc.Tokens)
+
+	re, _ := compileQuery("internationalization matters", false)
+	start, end, ok := index.find(re, 0, false)
+	if !ok || start != 1 || end != len(doc.Tokens) {
+		t.Errorf("find = [%d, %d) %v, want [1, %d)", start, end, ok, len(doc.Tokens))
+	}
+}
+
+func TestHasCapital(t *testing.T) {
+	tests := []struct {
+		query string
+		regex bool
+		want  bool
+	}{
+		{"foo", false, false},
+		{"Foo", false, true},
+		{`\S+ing`, true, false},
+		{`\S+ing`, false, true}, // Typed literally, S is a capital
+		{`\Wfoo`, true, false},
+		{`\p{Lu}x`, true, false},
+		{`\pLx`, true, false},
+		{`\\S`, true, true}, // An escaped backslash, then a capital
+		{`foo\`, true, false},
+	}
+	for _, tt := range tests {
+		if got := hasCapital(tt.query, tt.regex); got != tt.want {
+			t.Errorf("hasCapital(%q, %v) = %v, want %v", tt.query, tt.regex, got, tt.want)
+		}
+	}
+}
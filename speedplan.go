@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// interval is one phase of interval training
+type interval struct {
+	Length time.Duration
+	WPM    int
+}
+
+// speedPlan varies the reading speed over a session: a warm-up ramp up to
+// speed, and interval training that cycles through set speeds. Changing
+// the speed with the arrow keys shifts the whole plan by the same amount.
+type speedPlan struct {
+	Base      int // Speed the plan was made for, from -wpm
+	Ramp      time.Duration
+	Intervals []interval
+}
+
+// Fraction of full speed the warm-up ramp starts at
+const rampStart = 0.5
+
+// at returns the speed to read at after elapsed reading time, given the
+// reader's target speed, and the phase of the plan for the status line
+// ("" when reading at a steady speed)
+func (p speedPlan) at(target int, elapsed time.Duration) (int, string) {
+	wpm, phase := target, ""
+
+	if len(p.Intervals) > 0 {
+		var cycle time.Duration
+		fastest := 0
+		for _, iv := range p.Intervals {
+			cycle += iv.Length
+			fastest = max(fastest, iv.WPM)
+		}
+		t := elapsed % cycle
+		for _, iv := range p.Intervals {
+			if t < iv.Length {
+				wpm = iv.WPM + target - p.Base
+				phase = "recovery"
+				if iv.WPM == fastest {
+					phase = "sprint"
+				}
+				break
+			}
+			t -= iv.Length
+		}
+	}
+
+	if elapsed < p.Ramp {
+		// Ease in and out so the speed doesn't jump at either end
+		x := elapsed.Seconds() / p.Ramp.Seconds()
+		eased := x * x * (3 - 2*x)
+		wpm = int(float64(wpm) * (rampStart + (1-rampStart)*eased))
+		phase = "warm-up"
+	}
+	return min(max(wpm, 10), 1000), phase
+}
+
+// parseIntervals reads an interval plan such as "2m@600,1m@350": phases of
+// a duration at a speed, repeated in order
+func parseIntervals(s string) ([]interval, error) {
+	var plan []interval
+	for _, phase := range strings.Split(s, ",") {
+		length, speed, ok := strings.Cut(strings.TrimSpace(phase), "@")
+		if !ok {
+			return nil, fmt.Errorf("%q: want duration@wpm, e.g. 2m@600", phase)
+		}
+		d, err := time.ParseDuration(length)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("%q: invalid duration %q", phase, length)
+		}
+		wpm, err := strconv.Atoi(speed)
+		if err != nil || wpm < 10 || wpm > 1000 {
+			return nil, fmt.Errorf("%q: speed must be 10-1000 WPM", phase)
+		}
+		plan = append(plan, interval{Length: d, WPM: wpm})
+	}
+	return plan, nil
+}